@@ -0,0 +1,76 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"pgn_check/pgn"
+)
+
+// runDedup implements the "dedup" subcommand: report duplicate games and optionally
+// write a deduplicated file
+func runDedup(args []string) {
+	flags := flag.NewFlagSet("dedup", flag.ExitOnError)
+	outputFile := flags.String("o", "", "Output file keeping only the most complete copy of each game")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		fmt.Println("Usage: pgn_check dedup [-o output.pgn] <file.pgn> [file.pgn...]")
+		fmt.Println("Example: pgn_check dedup twic1617.pgn mygames.pgn")
+		fmt.Println("         pgn_check dedup -o merged.pgn twic1617.pgn mygames.pgn")
+		os.Exit(exitUsage)
+	}
+
+	deduplicator := pgn.NewDeduplicator()
+	for _, filename := range flags.Args() {
+		// Check if file exists
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			fatalf(exitIO, "Error: file '%s' not found\n", filename)
+		}
+		if err := deduplicator.AddFile(filename); err != nil {
+			fatalf(exitIO, "Error reading file '%s': %v\n", filename, err)
+		}
+	}
+
+	groups := deduplicator.Groups()
+
+	// If -o specified, save the deduplicated games
+	if *outputFile != "" {
+		written, err := deduplicator.WriteDeduplicated(*outputFile)
+		if err != nil {
+			fatalf(exitIO, "Error writing deduplicated file: %v\n", err)
+		}
+		fmt.Printf("✓ Deduplicated file saved to: %s (%d games)\n", *outputFile, written)
+	}
+
+	if len(groups) == 0 {
+		fmt.Printf("✓ No duplicate games found in %d games\n", deduplicator.GameCount())
+		os.Exit(0)
+	}
+
+	redundant := 0
+	for _, group := range groups {
+		redundant += len(group.Games) - 1
+	}
+
+	fmt.Printf("✗ Found %d duplicate groups (%d redundant games) in %d games:\n\n",
+		len(groups), redundant, deduplicator.GameCount())
+	for n, group := range groups {
+		fmt.Printf("Group %d: %s\n", n+1, group.Kind)
+		for _, i := range group.Games {
+			if i == group.Keep {
+				fmt.Printf("  %s [kept]\n", deduplicator.Describe(i))
+			} else {
+				fmt.Printf("  %s\n", deduplicator.Describe(i))
+			}
+		}
+	}
+	os.Exit(exitInvalid)
+}
@@ -0,0 +1,91 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"pgn_check/pgn"
+)
+
+// runExport implements the "export" subcommand: write the games of PGN files as JSON or CSV
+func runExport(args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	outputFile := flags.String("o", "", "Output file (default: standard output)")
+	format := flags.String("format", "json", "Output format: json (an array of games), ndjson (one game per line) or csv (one row per game)")
+	tags := flags.String("tags", strings.Join(pgn.DefaultCSVTags, ","), "Comma-separated tag columns of the csv game table")
+	movesFile := flags.String("moves", "", "With -format csv, also write one row per mainline move to this file")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		fmt.Println("Usage: pgn_check export [-format json|ndjson|csv] [-tags Event,White,...] [-moves moves.csv] [-o output] <file.pgn> [file.pgn...]")
+		fmt.Println("Example: pgn_check export -o games.json twic1617.pgn")
+		fmt.Println("         pgn_check export -format ndjson twic1617.pgn > games.ndjson")
+		fmt.Println("         pgn_check export -format csv -moves moves.csv -o games.csv twic1617.pgn")
+		os.Exit(exitUsage)
+	}
+	if *format != "json" && *format != "ndjson" && *format != "csv" {
+		fatalf(exitUsage, "Error: unknown export format '%s' (expected json, ndjson or csv)\n", *format)
+	}
+	if *movesFile != "" && *format != "csv" {
+		fatalf(exitUsage, "Error: -moves requires -format csv\n")
+	}
+
+	for _, filename := range flags.Args() {
+		// Check if file exists
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			fatalf(exitIO, "Error: file '%s' not found\n", filename)
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			fatalf(exitIO, "Error creating output file: %v\n", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	var written int
+	var err error
+	if *format == "csv" {
+		var moves io.Writer
+		if *movesFile != "" {
+			file, err := os.Create(*movesFile)
+			if err != nil {
+				fatalf(exitIO, "Error creating moves file: %v\n", err)
+			}
+			defer file.Close()
+			moves = file
+		}
+		var columns []string
+		for _, tag := range strings.Split(*tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				columns = append(columns, tag)
+			}
+		}
+		written, err = pgn.WriteCSVFiles(flags.Args(), out, moves, columns)
+	} else {
+		written, err = pgn.WriteJSONFiles(flags.Args(), out, *format == "ndjson")
+	}
+	if err != nil {
+		fatalf(exitIO, "Error exporting games: %v\n", err)
+	}
+
+	if *outputFile != "" {
+		fmt.Printf("✓ Exported %d games to: %s\n", written, *outputFile)
+	}
+	if *movesFile != "" {
+		fmt.Printf("✓ Moves saved to: %s\n", *movesFile)
+	}
+}
@@ -0,0 +1,74 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"pgn_check/pgn"
+)
+
+// runExtract implements the "extract" subcommand: write the games matching a query
+func runExtract(args []string) {
+	flags := flag.NewFlagSet("extract", flag.ExitOnError)
+	outputFile := flags.String("o", "", "Output file (default: standard output)")
+	canonical := flags.Bool("fmt", false, "Write the games in PGN export format instead of copying their lines")
+	aliasFile := flags.String("aliases", "", "Player alias file used to normalize White and Black names")
+	flags.Parse(args)
+
+	if flags.NArg() < 2 {
+		fmt.Println("Usage: pgn_check extract [-o output.pgn] [-fmt] [-aliases aliases.txt] <query> <file.pgn> [file.pgn...]")
+		fmt.Println(`Example: pgn_check extract 'White~"Carlsen" && Date>=2023.01.01 && Result=="1-0"' twic1617.pgn`)
+		fmt.Println(`         pgn_check extract -o sicilian.pgn 'ECO>=B20 && ECO<=B99' twic1617.pgn`)
+		fmt.Println(`         pgn_check extract -fmt 'Player~"Nakamura" || Event~"Titled Arena"' twic1617.pgn`)
+		os.Exit(exitUsage)
+	}
+
+	validator := pgn.NewPGNValidator()
+	if *aliasFile != "" {
+		if err := validator.LoadAliases(*aliasFile); err != nil {
+			fatalf(exitIO, "Error loading aliases: %v\n", err)
+		}
+	}
+
+	query, err := pgn.ParseQuery(flags.Arg(0), validator)
+	if err != nil {
+		fatalf(exitUsage, "Error: invalid query: %v\n", err)
+	}
+
+	filenames := flags.Args()[1:]
+	for _, filename := range filenames {
+		// Check if file exists
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			fatalf(exitIO, "Error: file '%s' not found\n", filename)
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			fatalf(exitIO, "Error creating output file: %v\n", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	matched, total, err := validator.WriteExtractedFiles(filenames, query, out, *canonical)
+	if err != nil {
+		fatalf(exitIO, "Error extracting games: %v\n", err)
+	}
+
+	// The games may be written to the standard output, report the count on standard error
+	fmt.Fprintf(os.Stderr, "✓ Extracted %d of %d games\n", matched, total)
+	if *outputFile != "" {
+		fmt.Printf("✓ Extracted games saved to: %s\n", *outputFile)
+	}
+}
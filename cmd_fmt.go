@@ -0,0 +1,69 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"pgn_check/pgn"
+)
+
+// runFormat implements the "fmt" subcommand: rewrite a PGN file in PGN export format
+func runFormat(args []string) {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	outputFile := flags.String("o", "", "Output file (default: standard output)")
+	aliasFile := flags.String("aliases", "", "Player alias file used to normalize White and Black names")
+	nagStyle := flags.String("nag", "keep", "Annotation style of the output: keep, numeric ($5) or symbolic (!?)")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		fmt.Println("Usage: pgn_check fmt [-o output.pgn] [-aliases aliases.txt] [-nag keep|numeric|symbolic] <file.pgn>")
+		fmt.Println("Example: pgn_check fmt game.pgn")
+		fmt.Println("         pgn_check fmt -o formatted.pgn game.pgn")
+		os.Exit(exitUsage)
+	}
+
+	filename := flags.Arg(0)
+
+	// Check if file exists
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		fatalf(exitIO, "Error: file '%s' not found\n", filename)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			fatalf(exitIO, "Error creating output file: %v\n", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	style, err := pgn.ParseAnnotationStyle(*nagStyle)
+	if err != nil {
+		fatalf(exitUsage, "Error: %v\n", err)
+	}
+
+	validator := pgn.NewPGNValidator()
+	validator.SetAnnotationStyle(style)
+	if *aliasFile != "" {
+		if err := validator.LoadAliases(*aliasFile); err != nil {
+			fatalf(exitIO, "Error loading aliases: %v\n", err)
+		}
+	}
+	if err := validator.WriteFormattedFile(filename, out); err != nil {
+		fatalf(exitIO, "Error formatting file: %v\n", err)
+	}
+
+	if *outputFile != "" {
+		fmt.Printf("✓ Formatted file saved to: %s\n", *outputFile)
+	}
+}
@@ -0,0 +1,153 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"pgn_check/pgn"
+)
+
+// importFormat returns the input format of a file for -from auto, from its extension
+func importFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json", ".ndjson":
+		return "json"
+	case ".epd", ".fen":
+		return "epd"
+	}
+	return "moves"
+}
+
+// runImport implements the "import" subcommand: convert games exported as JSON, position
+// lists and move lists in other notations to PGN, then validate the result
+func runImport(args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	outputFile := flags.String("o", "", "Output file (default: standard output)")
+	from := flags.String("from", "auto", "Input format: json, epd (EPD or FEN lines), moves (UCI, LAN, figurine or SAN move lists) or auto (from the file extension)")
+	language := flags.String("lang", "en", "Piece letters of SAN moves: en, de (S L T D), it (C A T D R) or fr (C F T D R)")
+	failOn := flags.String("fail-on", "error", "Lowest severity of the messages failing the import: error, warning, info or never")
+	maxWarnings := flags.Int("max-warnings", -1, "Fail the import with more than this many warnings (-1: no limit)")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Println("Usage: pgn_check import [-from auto|json|epd|moves] [-lang en|de|it|fr] [-fail-on error|warning|info|never] [-max-warnings N] [-o output.pgn] <file>")
+		fmt.Println("Example: pgn_check import -o games.pgn games.json")
+		fmt.Println("         pgn_check import games.ndjson > games.pgn")
+		fmt.Println("         pgn_check import -o positions.pgn positions.epd")
+		fmt.Println("         pgn_check import -from moves -lang de -o games.pgn partien.txt")
+		os.Exit(exitUsage)
+	}
+
+	filename := flags.Arg(0)
+	format := *from
+	if format == "auto" {
+		format = importFormat(filename)
+	}
+	if format != "json" && format != "epd" && format != "moves" {
+		fatalf(exitUsage, "Error: unknown import format '%s' (expected auto, json, epd or moves)\n", format)
+	}
+	converter, err := pgn.NewMoveConverter(*language)
+	if err != nil {
+		fatalf(exitUsage, "Error: %v\n", err)
+	}
+	policy, err := parseFailPolicy(*failOn, *maxWarnings)
+	if err != nil {
+		fatalf(exitUsage, "Error: %v\n", err)
+	}
+
+	input, err := os.Open(filename)
+	if err != nil {
+		fatalf(exitIO, "Error: file '%s' not found\n", filename)
+	}
+	defer input.Close()
+
+	var out io.Writer = os.Stdout
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			fatalf(exitIO, "Error creating output file: %v\n", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	// The imported games are validated while they are written
+	reader, writer := io.Pipe()
+	validated := make(chan []pgn.ValidationError)
+	go func() {
+		errors, _ := pgn.NewPGNValidator().ValidateReader(context.Background(), reader, pgn.ValidateOptions{})
+		io.Copy(io.Discard, reader)
+		validated <- errors
+	}()
+	out = io.MultiWriter(out, writer)
+
+	validator := pgn.NewPGNValidator()
+	var written int
+	var problems []pgn.ValidationError
+	switch format {
+	case "json":
+		written, err = validator.ImportJSON(input, out)
+	case "epd":
+		written, problems, err = validator.ImportEPD(input, out)
+	default:
+		written, problems, err = validator.ImportMoveLists(input, out, converter)
+	}
+	writer.Close()
+	errors := <-validated
+	if err != nil {
+		fatalf(exitIO, "Error importing games: %v\n", err)
+	}
+
+	// Messages go to standard error when the games are written to standard output
+	var report io.Writer = os.Stdout
+	if *outputFile == "" {
+		report = os.Stderr
+	} else {
+		fmt.Fprintf(report, "✓ Imported %d games to: %s\n", written, *outputFile)
+	}
+
+	if len(problems) == 0 && len(errors) == 0 {
+		return
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(report, "✗ Could not convert %d entries of %s:\n\n", len(problems), filename)
+		for _, problem := range problems {
+			fmt.Fprintln(report, problem)
+		}
+		fmt.Fprintln(report)
+	}
+
+	// Entries that could not be converted are errors; the validation messages fail the import
+	// according to -fail-on and -max-warnings, as for the validation of a file
+	counts := map[pgn.Severity]int{}
+	for _, e := range errors {
+		counts[e.Severity]++
+	}
+	if len(errors) > 0 {
+		switch code, failure := policy.exitCode(counts); code {
+		case exitOK:
+			fmt.Fprintf(report, "✓ Imported games are valid (%s):\n\n", pgn.DescribeCounts(counts))
+		case exitWarnings:
+			fmt.Fprintf(report, "✗ %s in imported games (failing with %s):\n\n", pgn.DescribeCounts(counts), failure)
+		default:
+			fmt.Fprintf(report, "✗ Found %s in imported games:\n\n", pgn.DescribeCounts(counts))
+		}
+		for _, err := range errors {
+			fmt.Fprintln(report, err)
+		}
+	}
+	counts[pgn.SeverityError] += len(problems)
+	code, _ := policy.exitCode(counts)
+	os.Exit(code)
+}
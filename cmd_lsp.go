@@ -0,0 +1,46 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"pgn_check/lsp"
+	"pgn_check/pgn"
+)
+
+// runLSP implements the "lsp" subcommand: a language server for editors, speaking the
+// Language Server Protocol over standard input and output
+func runLSP(args []string) {
+	flags := flag.NewFlagSet("lsp", flag.ExitOnError)
+	aliasFile := flags.String("aliases", "", "Player alias file used to normalize White and Black names")
+	nagStyle := flags.String("nag", "keep", "Annotation style of corrections and formatting: keep, numeric ($5) or symbolic (!?)")
+	openingTags := flags.Bool("eco", false, "Add missing ECO, Opening and Variation tags and fix wrong ECO tags")
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		fmt.Println("Usage: pgn_check lsp [-aliases aliases.txt] [-nag keep|numeric|symbolic] [-eco]")
+		fmt.Println("Example: pgn_check lsp -aliases players.txt")
+		os.Exit(exitUsage)
+	}
+
+	style, err := pgn.ParseAnnotationStyle(*nagStyle)
+	if err != nil {
+		fatalf(exitUsage, "Error: %v\n", err)
+	}
+	newValidator, err := validatorFactory(*aliasFile, style, *openingTags)
+	if err != nil {
+		fatalf(exitIO, "Error: %v\n", err)
+	}
+
+	server := lsp.New(lsp.Config{Version: Version, NewValidator: newValidator})
+	if err := server.Serve(os.Stdin, os.Stdout); err != nil {
+		fatalf(exitIO, "Error: %v\n", err)
+	}
+}
@@ -0,0 +1,58 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"pgn_check/pgn"
+)
+
+// runMerge implements the "merge" subcommand: concatenate the games of PGN files
+func runMerge(args []string) {
+	flags := flag.NewFlagSet("merge", flag.ExitOnError)
+	outputFile := flags.String("o", "", "Output file (default: standard output)")
+	canonical := flags.Bool("fmt", false, "Write the games in PGN export format instead of copying their lines")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		fmt.Println("Usage: pgn_check merge [-o output.pgn] [-fmt] <file.pgn> [file.pgn...]")
+		fmt.Println("Example: pgn_check merge -o all.pgn twic1617.pgn twic1618.pgn")
+		fmt.Println("         pgn_check merge -fmt events/*.pgn > all.pgn")
+		os.Exit(exitUsage)
+	}
+
+	for _, filename := range flags.Args() {
+		// Check if file exists
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			fatalf(exitIO, "Error: file '%s' not found\n", filename)
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if *outputFile != "" {
+		file, err := os.Create(*outputFile)
+		if err != nil {
+			fatalf(exitIO, "Error creating output file: %v\n", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	validator := pgn.NewPGNValidator()
+	written, err := validator.WriteMergedFiles(flags.Args(), out, *canonical)
+	if err != nil {
+		fatalf(exitIO, "Error merging files: %v\n", err)
+	}
+
+	if *outputFile != "" {
+		fmt.Printf("✓ Merged file saved to: %s (%d games)\n", *outputFile, written)
+	}
+}
@@ -0,0 +1,59 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"pgn_check/pgn"
+)
+
+// runSearch implements the "search" subcommand: find games reaching a position or a
+// material configuration
+func runSearch(args []string) {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	fen := flags.String("fen", "", "Position to find, in FEN (move counters are ignored)")
+	signature := flags.String("material", "", "Material to find, e.g. KRPvKR (either side may have the first part)")
+	variations := flags.Bool("variations", false, "Also search the variations")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 || (*fen == "" && *signature == "") {
+		fmt.Println("Usage: pgn_check search [-fen FEN] [-material KRPvKR] [-variations] <file.pgn> [file.pgn...]")
+		fmt.Println(`Example: pgn_check search -fen "rnbqkb1r/1p2pppp/p2p1n2/8/3NP3/2N5/PPP2PPP/R1BQKB1R w KQkq - 0 6" twic1617.pgn`)
+		fmt.Println("         pgn_check search -material KRPvKR twic1617.pgn")
+		os.Exit(exitUsage)
+	}
+
+	search, err := pgn.NewPositionSearch(*fen, *signature, *variations)
+	if err != nil {
+		fatalf(exitUsage, "Error: %v\n", err)
+	}
+
+	matches, games := 0, 0
+	for _, filename := range flags.Args() {
+		// Check if file exists
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			fatalf(exitIO, "Error: file '%s' not found\n", filename)
+		}
+		n, err := search.SearchFile(filename, func(m pgn.PositionMatch) {
+			fmt.Println(m)
+			matches++
+		})
+		if err != nil {
+			fatalf(exitIO, "Error reading file '%s': %v\n", filename, err)
+		}
+		games += n
+	}
+
+	if matches == 0 {
+		fmt.Printf("✗ No games found in %d games\n", games)
+		os.Exit(1)
+	}
+	fmt.Printf("\n✓ Found %d of %d games\n", matches, games)
+}
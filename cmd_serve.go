@@ -0,0 +1,102 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"pgn_check/pgn"
+	"pgn_check/server"
+)
+
+// runServe implements the "serve" subcommand: validate, correct and format PGN sent over HTTP
+func runServe(args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := flags.String("addr", ":8080", "Address to listen on")
+	maxBody := flags.Int64("max-body", server.DefaultMaxBodySize/(1024*1024), "Largest PGN body accepted, in MB after decompression")
+	timeout := flags.Duration("timeout", server.DefaultTimeout, "Longest time spent on a request")
+	concurrency := flags.Int("concurrency", runtime.NumCPU(), "Requests processed at once; more are answered 503 Service Unavailable")
+	aliasFile := flags.String("aliases", "", "Player alias file used to normalize White and Black names")
+	nagStyle := flags.String("nag", "keep", "Annotation style of the output: keep, numeric ($5) or symbolic (!?)")
+	openingTags := flags.Bool("eco", false, "Add missing ECO, Opening and Variation tags and fix wrong ECO tags")
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		fmt.Println("Usage: pgn_check serve [-addr :8080] [-max-body MB] [-timeout 1m] [-concurrency N] [-aliases aliases.txt] [-nag keep|numeric|symbolic] [-eco]")
+		fmt.Println("Example: pgn_check serve -addr localhost:8080")
+		fmt.Println("         curl --data-binary @game.pgn http://localhost:8080/validate")
+		os.Exit(exitUsage)
+	}
+
+	style, err := pgn.ParseAnnotationStyle(*nagStyle)
+	if err != nil {
+		fatalf(exitUsage, "Error: %v\n", err)
+	}
+	newValidator, err := validatorFactory(*aliasFile, style, *openingTags)
+	if err != nil {
+		fatalf(exitIO, "Error: %v\n", err)
+	}
+
+	srv := &http.Server{
+		Addr: *addr,
+		Handler: server.New(server.Config{
+			MaxBodySize:   *maxBody * 1024 * 1024,
+			Timeout:       *timeout,
+			MaxConcurrent: *concurrency,
+			NewValidator:  newValidator,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       *timeout,
+		WriteTimeout:      2 * *timeout,
+		IdleTimeout:       2 * time.Minute,
+	}
+
+	// Stop on Ctrl+C or SIGTERM, letting requests being processed finish
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stopped := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+		srv.Shutdown(shutdown)
+		close(stopped)
+	}()
+
+	fmt.Printf("✓ Listening on %s\n", *addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fatalf(exitIO, "Error: %v\n", err)
+	}
+	<-stopped
+}
+
+// validatorFactory returns a function creating validators with the given -aliases, -nag and
+// -eco settings, for commands validating many inputs. The alias file is read once here.
+func validatorFactory(aliasFile string, style pgn.AnnotationStyle, openingTags bool) (func() *pgn.PGNValidator, error) {
+	aliases := pgn.NewPGNValidator()
+	if aliasFile != "" {
+		if err := aliases.LoadAliases(aliasFile); err != nil {
+			return nil, fmt.Errorf("cannot load aliases: %v", err)
+		}
+	}
+	return func() *pgn.PGNValidator {
+		validator := pgn.NewPGNValidator()
+		validator.SetAnnotationStyle(style)
+		validator.SetOpeningTags(openingTags)
+		validator.ShareAliases(aliases)
+		return validator
+	}, nil
+}
@@ -0,0 +1,75 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"pgn_check/pgn"
+)
+
+// runSplit implements the "split" subcommand: write the games of PGN files to one file per
+// event, player, month or number of games
+func runSplit(args []string) {
+	flags := flag.NewFlagSet("split", flag.ExitOnError)
+	by := flags.String("by", "event", "Group games by event, player, month or count")
+	count := flags.Int("n", 1000, "Games per file with -by count")
+	dir := flags.String("dir", ".", "Output directory, created if missing")
+	canonical := flags.Bool("fmt", false, "Write the games in PGN export format instead of copying their lines")
+	aliasFile := flags.String("aliases", "", "Player alias file used to normalize White and Black names")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		fmt.Println("Usage: pgn_check split [-by event|player|month|count] [-n 1000] [-dir directory] [-fmt] [-aliases aliases.txt] <file.pgn> [file.pgn...]")
+		fmt.Println("Example: pgn_check split -dir events twic1617.pgn")
+		fmt.Println("         pgn_check split -by month -dir months twic1617.pgn twic1618.pgn")
+		fmt.Println("         pgn_check split -by count -n 500 -dir chunks twic1617.pgn")
+		os.Exit(exitUsage)
+	}
+
+	mode, err := pgn.ParseSplitMode(*by)
+	if err != nil {
+		fatalf(exitUsage, "Error: %v\n", err)
+	}
+	if *count < 1 {
+		fatalf(exitUsage, "Error: -n must be at least 1\n")
+	}
+
+	validator := pgn.NewPGNValidator()
+	if *aliasFile != "" {
+		if err := validator.LoadAliases(*aliasFile); err != nil {
+			fatalf(exitIO, "Error loading aliases: %v\n", err)
+		}
+	}
+
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		fatalf(exitIO, "Error creating output directory: %v\n", err)
+	}
+
+	splitter := pgn.NewSplitter(validator, mode, *count, *dir, *canonical)
+	for _, filename := range flags.Args() {
+		// Check if file exists
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			fatalf(exitIO, "Error: file '%s' not found\n", filename)
+		}
+		if err := splitter.SplitFile(filename); err != nil {
+			splitter.Close()
+			fatalf(exitIO, "Error splitting file '%s': %v\n", filename, err)
+		}
+	}
+	if err := splitter.Close(); err != nil {
+		fatalf(exitIO, "Error splitting files: %v\n", err)
+	}
+
+	games := 0
+	for _, name := range splitter.Files() {
+		games += splitter.Counts[name]
+	}
+	fmt.Printf("✓ Wrote %d games to %d files in: %s\n", games, len(splitter.Counts), *dir)
+}
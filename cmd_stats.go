@@ -0,0 +1,77 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"pgn_check/pgn"
+)
+
+// runStats implements the "stats" subcommand: validate PGN files and report statistics
+func runStats(args []string) {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	jsonOutput := flags.Bool("json", false, "Write the statistics as JSON")
+	aliasFile := flags.String("aliases", "", "Player alias file used to normalize White and Black names")
+	progressMode := flags.String("progress", "auto", "Progress display: auto (a bar on terminals, none with -json), always, never or json (events on standard error)")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 {
+		fmt.Println("Usage: pgn_check stats [-json] [-aliases aliases.txt] [-progress auto|always|never|json] <file.pgn> [file.pgn...]")
+		fmt.Println("Example: pgn_check stats twic1617.pgn")
+		fmt.Println("         pgn_check stats -json twic1617.pgn > stats.json")
+		os.Exit(exitUsage)
+	}
+
+	// The bar would mix with the JSON statistics on a terminal
+	if *jsonOutput && *progressMode == "auto" {
+		*progressMode = "never"
+	}
+	progress, err := newProgress(*progressMode)
+	if err != nil {
+		fatalf(exitUsage, "Error: %v\n", err)
+	}
+
+	validator := pgn.NewPGNValidator()
+	validator.SetProgress(progress)
+	if *aliasFile != "" {
+		if err := validator.LoadAliases(*aliasFile); err != nil {
+			fatalf(exitIO, "Error loading aliases: %v\n", err)
+		}
+	}
+
+	stats := pgn.NewStats(validator)
+	for _, filename := range flags.Args() {
+		// Check if file exists
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			fatalf(exitIO, "Error: file '%s' not found\n", filename)
+		}
+		errors, _ := validator.ValidateFileWith(filename, pgn.ValidateOptions{Visit: stats.AddGame})
+		stats.AddErrors(errors)
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(stats); err != nil {
+			fatalf(exitIO, "Error writing statistics: %v\n", err)
+		}
+		return
+	}
+
+	if flags.NArg() == 1 {
+		fmt.Printf("Statistics for %s:\n\n", flags.Arg(0))
+	} else {
+		fmt.Printf("Statistics for %d files:\n\n", flags.NArg())
+	}
+	if err := stats.WriteText(os.Stdout); err != nil {
+		fatalf(exitIO, "Error writing statistics: %v\n", err)
+	}
+}
@@ -0,0 +1,56 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"pgn_check/pgn"
+	"pgn_check/watch"
+)
+
+// runWatch implements the "watch" subcommand: revalidate the PGN files of a directory when
+// they change, printing the errors added and removed
+func runWatch(args []string) {
+	flags := flag.NewFlagSet("watch", flag.ExitOnError)
+	poll := flags.Bool("poll", false, "Scan the directory periodically instead of using filesystem notifications (e.g. for network drives)")
+	interval := flags.Duration("interval", watch.DefaultInterval, "Time between scans with -poll")
+	aliasFile := flags.String("aliases", "", "Player alias file used to normalize White and Black names")
+	openingTags := flags.Bool("eco", false, "Check ECO tags against the opening played")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Println("Usage: pgn_check watch [-poll] [-interval 1s] [-aliases aliases.txt] [-eco] <directory>")
+		fmt.Println("Example: pgn_check watch repertoire")
+		fmt.Println("         pgn_check watch -poll -interval 5s //server/share/games")
+		os.Exit(exitUsage)
+	}
+
+	dir := flags.Arg(0)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		fatalf(exitIO, "Error: directory '%s' not found\n", dir)
+	}
+
+	newValidator, err := validatorFactory(*aliasFile, pgn.AnnotationKeep, *openingTags)
+	if err != nil {
+		fatalf(exitIO, "Error: %v\n", err)
+	}
+
+	// Stop on Ctrl+C or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	watcher := watch.New(watch.Config{Poll: *poll, Interval: *interval, NewValidator: newValidator})
+	if err := watcher.Run(ctx, dir, os.Stdout); err != nil {
+		fatalf(exitIO, "Error: %v\n", err)
+	}
+}
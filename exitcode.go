@@ -0,0 +1,72 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"pgn_check/pgn"
+)
+
+// Exit codes of the validation
+const (
+	exitOK       = 0 // valid, or the messages found do not fail the -fail-on policy
+	exitInvalid  = 1 // errors found
+	exitUsage    = 2 // invalid arguments, as for flag parsing errors
+	exitWarnings = 3 // no errors, but warnings or informational messages fail the policy
+	exitIO       = 4 // a file cannot be read or written
+)
+
+// fatalf prints an error like log.Fatalf, exiting with the given code
+func fatalf(code int, format string, v ...any) {
+	log.Printf(format, v...)
+	os.Exit(code)
+}
+
+// failPolicy decides which validation messages fail the validation
+type failPolicy struct {
+	never       bool         // -fail-on never: no severity fails
+	failOn      pgn.Severity // lowest severity failing
+	maxWarnings int          // warnings allowed whatever -fail-on is, -1 for no limit
+}
+
+// parseFailPolicy returns the policy of the -fail-on and -max-warnings flags
+func parseFailPolicy(failOn string, maxWarnings int) (failPolicy, error) {
+	policy := failPolicy{maxWarnings: maxWarnings}
+	if strings.EqualFold(failOn, "never") {
+		policy.never = true
+		return policy, nil
+	}
+	severity, err := pgn.ParseSeverity(failOn)
+	if err != nil {
+		return policy, fmt.Errorf("invalid -fail-on: %v", err)
+	}
+	policy.failOn = severity
+	return policy, nil
+}
+
+// exitCode returns the exit code of a validation finding the given number of messages of
+// each severity, and for exitWarnings the setting failing it, e.g. "-fail-on warning"
+func (p failPolicy) exitCode(counts map[pgn.Severity]int) (int, string) {
+	if !p.never && counts[pgn.SeverityError] > 0 {
+		return exitInvalid, ""
+	}
+	if p.maxWarnings >= 0 && counts[pgn.SeverityWarning] > p.maxWarnings {
+		return exitWarnings, fmt.Sprintf("-max-warnings %d", p.maxWarnings)
+	}
+	if !p.never {
+		for _, s := range []pgn.Severity{pgn.SeverityWarning, pgn.SeverityInfo} {
+			if s.AtLeast(p.failOn) && counts[s] > 0 {
+				return exitWarnings, fmt.Sprintf("-fail-on %s", p.failOn)
+			}
+		}
+	}
+	return exitOK, ""
+}
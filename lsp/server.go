@@ -0,0 +1,463 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+// Package lsp is a Language Server Protocol server for PGN files, behind "pgn_check lsp".
+// It publishes validation errors as diagnostics while a file is edited, offers the
+// validator's corrections as code actions, formats documents in PGN export format, shows the
+// position after a move on hover and folds variations and long comments.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"pgn_check/pgn"
+)
+
+// ErrNoShutdown is returned by Serve when the client exits without asking for a shutdown
+var ErrNoShutdown = errors.New("exit without shutdown")
+
+// Config holds the settings of a Server
+type Config struct {
+	Version      string                   // version reported to the client
+	NewValidator func() *pgn.PGNValidator // validator of each request (default: pgn.NewPGNValidator)
+}
+
+// Server is a language server for PGN files
+type Server struct {
+	config    Config
+	out       io.Writer
+	documents map[string]*document // open documents by URI
+	shutdown  bool                 // the client asked for a shutdown
+}
+
+// document is an open text document
+type document struct {
+	version int
+	text    string
+	lines   []string // lines without line terminators
+}
+
+// New returns a Server with the given settings
+func New(config Config) *Server {
+	if config.NewValidator == nil {
+		config.NewValidator = pgn.NewPGNValidator
+	}
+	return &Server{config: config, documents: make(map[string]*document)}
+}
+
+// Serve answers the messages read from r, writing to w, until the client exits
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.out = w
+	in := bufio.NewReader(r)
+	for {
+		msg, err := readMessage(in)
+		if err != nil {
+			if err == io.EOF {
+				return ErrNoShutdown
+			}
+			return err
+		}
+		if msg.Error != nil {
+			// The message is not valid JSON
+			if err := s.reply(json.RawMessage("null"), nil, msg.Error); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrNoShutdown
+			}
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handle answers a request or processes a notification
+func (s *Server) handle(msg *message) error {
+	if msg.Method == "" {
+		// Responses of the client are not expected since the server sends no requests
+		return nil
+	}
+
+	var result any
+	var err error
+	switch msg.Method {
+	case "initialize":
+		result = map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":           map[string]any{"openClose": true, "change": 1}, // full text
+				"codeActionProvider":         map[string]any{"codeActionKinds": []string{kindQuickFix, kindFixAll}},
+				"documentFormattingProvider": true,
+				"hoverProvider":              true,
+				"foldingRangeProvider":       true,
+			},
+			"serverInfo": map[string]string{"name": "pgn_check", "version": s.config.Version},
+		}
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			s.documents[params.TextDocument.URI] = newDocument(params.TextDocument.Text, params.TextDocument.Version)
+			return s.publishDiagnostics(params.TextDocument.URI)
+		}
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil && len(params.ContentChanges) > 0 {
+			text := params.ContentChanges[len(params.ContentChanges)-1].Text
+			s.documents[params.TextDocument.URI] = newDocument(text, params.TextDocument.Version)
+			return s.publishDiagnostics(params.TextDocument.URI)
+		}
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			delete(s.documents, params.TextDocument.URI)
+			return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: params.TextDocument.URI, Diagnostics: []diagnostic{}})
+		}
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			result = s.codeActions(params)
+		}
+	case "textDocument/formatting":
+		var params documentParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			result, err = s.format(params.TextDocument.URI)
+		}
+	case "textDocument/hover":
+		var params positionParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			result = s.hover(params)
+		}
+	case "textDocument/foldingRange":
+		var params documentParams
+		if err = json.Unmarshal(msg.Params, &params); err == nil {
+			result = s.foldingRanges(params.TextDocument.URI)
+		}
+	default:
+		if msg.ID != nil {
+			return s.reply(msg.ID, nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method '%s' not supported", msg.Method)})
+		}
+		return nil
+	}
+
+	// Notifications get no answer
+	if msg.ID == nil {
+		return nil
+	}
+	if err != nil {
+		return s.reply(msg.ID, nil, &responseError{Code: codeInvalidParams, Message: err.Error()})
+	}
+	return s.reply(msg.ID, result, nil)
+}
+
+// reply writes the response to the request with the given id
+func (s *Server) reply(id json.RawMessage, result any, respErr *responseError) error {
+	msg := &message{ID: id, Error: respErr}
+	if respErr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("cannot encode result: %v", err)
+		}
+		msg.Result = data
+	}
+	return writeMessage(s.out, msg)
+}
+
+// notify writes a notification to the client
+func (s *Server) notify(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("cannot encode notification: %v", err)
+	}
+	return writeMessage(s.out, &message{Method: method, Params: data})
+}
+
+func newDocument(text string, version int) *document {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
+	}
+	return &document{version: version, text: text, lines: lines}
+}
+
+// games returns the games of a document
+func (d *document) games() []*pgn.Game {
+	var games []*pgn.Game
+	pgn.ReadGames(context.Background(), strings.NewReader(d.text), func(g *pgn.Game) error {
+		games = append(games, g)
+		return nil
+	})
+	return games
+}
+
+// line returns a line of the document by its 1-based number, "" if out of range
+func (d *document) line(number int) string {
+	if number < 1 || number > len(d.lines) {
+		return ""
+	}
+	return d.lines[number-1]
+}
+
+// position returns the protocol position of a byte offset in a line given by its 1-based number
+func (d *document) position(line, col int) position {
+	text := d.line(line)
+	if col > len(text) {
+		col = len(text)
+	}
+	return position{Line: max(line-1, 0), Character: utf16Len(text[:col])}
+}
+
+// end returns the position of the end of the document
+func (d *document) end() position {
+	return d.position(len(d.lines), len(d.lines[len(d.lines)-1]))
+}
+
+// utf16Len returns the length of s in UTF-16 code units, the unit of protocol positions
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return n
+}
+
+// byteOffset returns the byte offset in s of a column in UTF-16 code units
+func byteOffset(s string, character int) int {
+	n := 0
+	for i, r := range s {
+		if n >= character {
+			return i
+		}
+		if r >= 0x10000 {
+			n += 2
+		} else {
+			n++
+		}
+	}
+	return len(s)
+}
+
+// publishDiagnostics sends the validation errors of a document
+func (s *Server) publishDiagnostics(uri string) error {
+	doc := s.documents[uri]
+	diagnostics := []diagnostic{}
+	for _, e := range s.config.NewValidator().ValidateString(doc.text) {
+		diagnostics = append(diagnostics, doc.diagnostic(e))
+	}
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Version: &doc.version, Diagnostics: diagnostics})
+}
+
+// diagnostic returns the diagnostic of a validation error, spanning the text of its line
+func (d *document) diagnostic(e pgn.ValidationError) diagnostic {
+	text := d.line(e.Line)
+	start := len(text) - len(strings.TrimLeft(text, " \t"))
+
+	severity := severityError
+	switch e.Severity {
+	case pgn.SeverityWarning:
+		severity = severityWarning
+	case pgn.SeverityInfo:
+		severity = severityInformation
+	}
+
+	return diagnostic{
+		Range:    textRange{Start: d.position(e.Line, start), End: d.position(e.Line, len(text))},
+		Severity: severity,
+		Code:     e.Rule,
+		Source:   "pgn_check",
+		Message:  strings.TrimPrefix(e.Message, "Warning: "),
+	}
+}
+
+// codeActions returns the corrections of the games in the requested range, and the
+// correction of the whole document
+func (s *Server) codeActions(params codeActionParams) []codeAction {
+	uri := params.TextDocument.URI
+	doc := s.documents[uri]
+	actions := []codeAction{}
+	if doc == nil {
+		return actions
+	}
+	validator := s.config.NewValidator()
+
+	// Requested lines, 1-based
+	first, last := params.Range.Start.Line+1, params.Range.End.Line+1
+	if hasKind(params.Context.Only, kindQuickFix) {
+		for _, game := range doc.games() {
+			if game.StartLine > last || game.StartLine+len(game.Lines)-1 < first {
+				continue
+			}
+			for _, fix := range validator.Fixes(game) {
+				if fix.Line > last || fix.EndLine < first {
+					continue
+				}
+				action := codeAction{Title: fix.Title, Kind: kindQuickFix, Edit: doc.workspaceEdit(uri, fix.Edits)}
+				for _, diag := range params.Context.Diagnostics {
+					line := diag.Range.Start.Line + 1
+					if line >= fix.Line && line <= fix.EndLine && containsRule(fix.Rules, diag.Code) {
+						action.Diagnostics = append(action.Diagnostics, diag)
+					}
+				}
+				actions = append(actions, action)
+			}
+		}
+	}
+
+	if hasKind(params.Context.Only, kindFixAll) {
+		var corrected strings.Builder
+		err := validator.CorrectGames(context.Background(), strings.NewReader(doc.text), &corrected)
+		if err == nil && corrected.String() != doc.text {
+			actions = append(actions, codeAction{
+				Title: "Apply all corrections",
+				Kind:  kindFixAll,
+				Edit:  workspaceEdit{Changes: map[string][]textEdit{uri: {doc.replaceAll(corrected.String())}}},
+			})
+		}
+	}
+	return actions
+}
+
+func containsRule(rules []string, rule string) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// workspaceEdit returns the protocol form of the edits of a fix
+func (d *document) workspaceEdit(uri string, edits []pgn.Edit) workspaceEdit {
+	textEdits := []textEdit{}
+	for _, edit := range edits {
+		textEdits = append(textEdits, textEdit{
+			Range:   textRange{Start: d.position(edit.Line, edit.Col), End: d.position(edit.Line, edit.EndCol)},
+			NewText: edit.NewText,
+		})
+	}
+	return workspaceEdit{Changes: map[string][]textEdit{uri: textEdits}}
+}
+
+// replaceAll returns the edit replacing the whole document with text
+func (d *document) replaceAll(text string) textEdit {
+	return textEdit{Range: textRange{End: d.end()}, NewText: text}
+}
+
+// format returns the edits writing a document in PGN export format
+func (s *Server) format(uri string) ([]textEdit, error) {
+	doc := s.documents[uri]
+	if doc == nil {
+		return nil, fmt.Errorf("document '%s' is not open", uri)
+	}
+	var formatted strings.Builder
+	if err := s.config.NewValidator().FormatGames(context.Background(), strings.NewReader(doc.text), &formatted); err != nil {
+		return nil, err
+	}
+	if formatted.String() == doc.text {
+		return []textEdit{}, nil
+	}
+	return []textEdit{doc.replaceAll(formatted.String())}, nil
+}
+
+// hover returns the position reached by the move under the cursor, nil if there is no move
+func (s *Server) hover(params positionParams) *hover {
+	doc := s.documents[params.TextDocument.URI]
+	if doc == nil {
+		return nil
+	}
+	line := params.Position.Line + 1
+	col := byteOffset(doc.line(line), params.Position.Character)
+
+	for _, game := range doc.games() {
+		if line < game.MovetextLine() || line >= game.StartLine+len(game.Lines) {
+			continue
+		}
+		node := findMove(game.MoveTree().Moves, line, col)
+		if node == nil {
+			return nil
+		}
+
+		number := fmt.Sprintf("%d.", node.MoveNumber())
+		if node.Color() == pgn.Black {
+			number += ".."
+		}
+		text := fmt.Sprintf("**%s %s**\n\n", number, node.SAN)
+		if node.Position == nil {
+			text += "The move cannot be played in this position."
+		} else {
+			text += "```\n" + node.Position.FEN() + "\n```"
+		}
+		return &hover{
+			Contents: markupContent{Kind: "markdown", Value: text},
+			Range:    textRange{Start: doc.position(line, node.Col), End: doc.position(line, node.EndCol)},
+		}
+	}
+	return nil
+}
+
+// findMove returns the move of a line of play or its variations written at a byte offset of
+// a line, nil if none
+func findMove(moves []*pgn.MoveNode, line, col int) *pgn.MoveNode {
+	for _, node := range moves {
+		if node.Line == line && col >= node.Col && col < node.EndCol {
+			return node
+		}
+		for _, variation := range node.Variations {
+			if found := findMove(variation, line, col); found != nil {
+				return found
+			}
+		}
+	}
+	return nil
+}
+
+// foldingRanges returns the variations and comments of a document spanning several lines
+func (s *Server) foldingRanges(uri string) []foldingRange {
+	ranges := []foldingRange{}
+	doc := s.documents[uri]
+	if doc == nil {
+		return ranges
+	}
+
+	for _, game := range doc.games() {
+		var open []pgn.Token // variations being read
+		for _, tok := range game.Tokens() {
+			switch tok.Kind {
+			case pgn.TokenVariationStart:
+				open = append(open, tok)
+			case pgn.TokenVariationEnd:
+				if len(open) == 0 {
+					continue
+				}
+				start := open[len(open)-1]
+				open = open[:len(open)-1]
+				if tok.Line > start.Line {
+					ranges = append(ranges, foldingRange{StartLine: start.Line - 1, EndLine: tok.Line - 1})
+				}
+			case pgn.TokenComment:
+				if tok.EndLine > tok.Line {
+					ranges = append(ranges, foldingRange{StartLine: tok.Line - 1, EndLine: tok.EndLine - 1, Kind: "comment"})
+				}
+			}
+		}
+	}
+	return ranges
+}
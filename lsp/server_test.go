@@ -0,0 +1,205 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const testDocument = "[Event \"Test\"]\n[Date \"2024-01-15\"]\n[Result \"*\"]\n\n1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Bc6\n(4. Ba4 Nf6\n5. O-O) dc6 {Exchange\nvariation} *\n"
+
+// session holds the responses of a Server by request id and the diagnostics it published by
+// document version
+type session struct {
+	responses   map[string]*message
+	diagnostics map[int][]diagnostic
+}
+
+// run sends messages to a Server and returns its answers
+func run(t *testing.T, messages ...map[string]any) *session {
+	t.Helper()
+	var in bytes.Buffer
+	for _, msg := range messages {
+		msg["jsonrpc"] = "2.0"
+		body, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	var out bytes.Buffer
+	if err := New(Config{Version: "test"}).Serve(&in, &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	s := &session{responses: map[string]*message{}, diagnostics: map[int][]diagnostic{}}
+	r := bufio.NewReader(&out)
+	for {
+		msg, err := readMessage(r)
+		if err != nil {
+			break
+		}
+		switch msg.Method {
+		case "textDocument/publishDiagnostics":
+			var params publishDiagnosticsParams
+			json.Unmarshal(msg.Params, &params)
+			version := -1
+			if params.Version != nil {
+				version = *params.Version
+			}
+			s.diagnostics[version] = params.Diagnostics
+		case "":
+			s.responses[string(msg.ID)] = msg
+		}
+	}
+	return s
+}
+
+// result decodes the result of the request with the given id
+func (s *session) result(t *testing.T, id int, v any) {
+	t.Helper()
+	msg := s.responses[strconv.Itoa(id)]
+	if msg == nil || msg.Error != nil {
+		t.Fatalf("Expected a result for request %d, got %+v", id, msg)
+	}
+	if err := json.Unmarshal(msg.Result, v); err != nil {
+		t.Fatalf("Invalid result for request %d: %v", id, err)
+	}
+}
+
+func request(id int, method string, params any) map[string]any {
+	return map[string]any{"id": id, "method": method, "params": params}
+}
+
+func notification(method string, params any) map[string]any {
+	return map[string]any{"method": method, "params": params}
+}
+
+var (
+	uri          = "file:///repertoire.pgn"
+	textDocument = map[string]any{"uri": uri}
+	open         = notification("textDocument/didOpen", map[string]any{"textDocument": map[string]any{"uri": uri, "version": 1, "text": testDocument}})
+	shutdown     = request(99, "shutdown", nil)
+	exit         = notification("exit", nil)
+)
+
+func TestInitializeAndDiagnostics(t *testing.T) {
+	s := run(t,
+		request(1, "initialize", map[string]any{"capabilities": map[string]any{}}),
+		notification("initialized", map[string]any{}),
+		open,
+		notification("textDocument/didChange", map[string]any{
+			"textDocument":   map[string]any{"uri": uri, "version": 2},
+			"contentChanges": []map[string]any{{"text": strings.Replace(testDocument, "2024-01-15", "2024.01.15", 1)}},
+		}),
+		request(2, "unknown/method", nil),
+		shutdown, exit)
+
+	var initialized struct {
+		Capabilities map[string]any `json:"capabilities"`
+	}
+	s.result(t, 1, &initialized)
+	for _, capability := range []string{"codeActionProvider", "documentFormattingProvider", "hoverProvider", "foldingRangeProvider"} {
+		if initialized.Capabilities[capability] == nil {
+			t.Errorf("Expected capability %s", capability)
+		}
+	}
+
+	diagnostics := s.diagnostics[1]
+	if len(diagnostics) == 0 {
+		t.Fatal("Expected diagnostics")
+	}
+	if d := diagnostics[0]; d.Range.Start.Line != 1 || d.Code != "date" || d.Severity != severityInformation || d.Range.End.Character != 19 {
+		t.Errorf("Unexpected diagnostic %+v", d)
+	}
+	if len(s.diagnostics[2]) != len(diagnostics)-1 || s.diagnostics[2][0].Code == "date" {
+		t.Errorf("Expected the date diagnostic to go after the change, got %+v", s.diagnostics[2])
+	}
+	if msg := s.responses["2"]; msg == nil || msg.Error == nil || msg.Error.Code != codeMethodNotFound {
+		t.Errorf("Expected method not found, got %+v", msg)
+	}
+}
+
+func TestCodeActions(t *testing.T) {
+	whole := map[string]any{"start": map[string]any{"line": 0, "character": 0}, "end": map[string]any{"line": 8, "character": 0}}
+	dateLine := map[string]any{"start": map[string]any{"line": 1, "character": 0}, "end": map[string]any{"line": 1, "character": 0}}
+	s := run(t, open,
+		request(1, "textDocument/codeAction", map[string]any{"textDocument": textDocument, "range": whole, "context": map[string]any{"diagnostics": []any{}}}),
+		request(2, "textDocument/codeAction", map[string]any{"textDocument": textDocument, "range": dateLine, "context": map[string]any{
+			"diagnostics": dateDiagnostics(),
+			"only":        []string{"quickfix"},
+		}}),
+		shutdown, exit)
+
+	var actions []codeAction
+	s.result(t, 1, &actions)
+	titles := []string{}
+	for _, action := range actions {
+		titles = append(titles, action.Title)
+	}
+	expected := "Correct date to 2024.01.15|Replace 'Bc6' with 'Bxc6'|Replace 'dc6' with 'dxc6'|Apply all corrections"
+	if strings.Join(titles, "|") != expected {
+		t.Fatalf("Expected actions %s, got %s", expected, strings.Join(titles, "|"))
+	}
+	edit := actions[2].Edit.Changes[uri][0]
+	if edit.Range.Start != (position{Line: 6, Character: 8}) || edit.Range.End != (position{Line: 6, Character: 11}) || edit.NewText != "dxc6" {
+		t.Errorf("Unexpected SAN edit %+v", edit)
+	}
+
+	s.result(t, 2, &actions)
+	if len(actions) != 1 || actions[0].Kind != kindQuickFix || len(actions[0].Diagnostics) != 1 {
+		t.Errorf("Expected the date fix for the date diagnostic, got %+v", actions)
+	}
+}
+
+// dateDiagnostics returns the diagnostic of the date of the test document
+func dateDiagnostics() []diagnostic {
+	return []diagnostic{{Range: textRange{Start: position{Line: 1}, End: position{Line: 1, Character: 19}}, Code: "date", Message: "Date auto-corrected"}}
+}
+
+func TestFormattingHoverAndFolding(t *testing.T) {
+	s := run(t, open,
+		request(1, "textDocument/formatting", map[string]any{"textDocument": textDocument, "options": map[string]any{"tabSize": 4}}),
+		request(2, "textDocument/hover", map[string]any{"textDocument": textDocument, "position": map[string]any{"line": 5, "character": 5}}),
+		request(3, "textDocument/hover", map[string]any{"textDocument": textDocument, "position": map[string]any{"line": 0, "character": 3}}),
+		request(4, "textDocument/foldingRange", map[string]any{"textDocument": textDocument}),
+		shutdown, exit)
+
+	var edits []textEdit
+	s.result(t, 1, &edits)
+	if len(edits) != 1 || edits[0].Range.End != (position{Line: 8}) || !strings.Contains(edits[0].NewText, "4. Bxc6 (4. Ba4 Nf6 5. O-O) 4... dxc6") {
+		t.Errorf("Unexpected formatting edits %+v", edits)
+	}
+
+	var h hover
+	s.result(t, 2, &h)
+	if !strings.Contains(h.Contents.Value, "**4. Ba4**") || !strings.Contains(h.Contents.Value, "r1bqkbnr/1ppp1ppp/p1n5/4p3/B3P3/5N2/PPPP1PPP/RNBQK2R b KQkq - 1 4") {
+		t.Errorf("Unexpected hover %q", h.Contents.Value)
+	}
+	if h.Range.Start != (position{Line: 5, Character: 4}) || h.Range.End != (position{Line: 5, Character: 7}) {
+		t.Errorf("Unexpected hover range %+v", h.Range)
+	}
+	if msg := s.responses["3"]; msg == nil || string(msg.Result) != "null" {
+		t.Errorf("Expected no hover outside moves, got %+v", msg)
+	}
+
+	var ranges []foldingRange
+	s.result(t, 4, &ranges)
+	expected := []foldingRange{{StartLine: 5, EndLine: 6}, {StartLine: 6, EndLine: 7, Kind: "comment"}}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("Unexpected folding ranges %+v", ranges)
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	in := strings.NewReader("Content-Length: 33\r\n\r\n{\"jsonrpc\":\"2.0\",\"method\":\"exit\"}")
+	if err := New(Config{}).Serve(in, &bytes.Buffer{}); err != ErrNoShutdown {
+		t.Errorf("Expected ErrNoShutdown, got %v", err)
+	}
+}
@@ -1,73 +1,233 @@
-// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
-//
-// Author: Nazario D'Apote <nazario.dapote@gmail.com>
-// License: MIT
-// Repository: https://github.com/nazariodapote/pgn_check
-
-package main
-
-import (
-	"flag"
-	"fmt"
-	"log"
-	"os"
-)
-
-// Version is set at build time using ldflags
-var Version = "dev"
-
-func main() {
-	// Flag definitions
-	outputFile := flag.String("o", "", "Output file with corrections applied")
-	version := flag.Bool("version", false, "Show version information")
-	versionShort := flag.Bool("v", false, "Show version information")
-	flag.Parse()
-
-	// Show version if requested
-	if *version || *versionShort {
-		fmt.Printf("pgn_check version %s\n", Version)
-		fmt.Println("Author: Nazario D'Apote <nazario.dapote@gmail.com>")
-		fmt.Println("License: MIT")
-		os.Exit(0)
-	}
-
-	// Check arguments
-	if flag.NArg() < 1 {
-		fmt.Println("Usage: pgn_check [-o output.pgn] [-v|--version] <file.pgn>")
-		fmt.Println("Example: pgn_check game.pgn")
-		fmt.Println("         pgn_check -o corrected.pgn game.pgn")
-		fmt.Println("         pgn_check --version")
-		os.Exit(1)
-	}
-
-	filename := flag.Arg(0)
-
-	// Check if file exists
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		log.Fatalf("Error: file '%s' not found\n", filename)
-	}
-
-	// Validate PGN file
-	validator := NewPGNValidator()
-	errors := validator.ValidateFile(filename)
-
-	// If -o specified, save corrected file
-	if *outputFile != "" {
-		if err := validator.WriteCorrectedFile(filename, *outputFile); err != nil {
-			log.Fatalf("Error writing corrected file: %v\n", err)
-		}
-		fmt.Printf("✓ Corrected file saved to: %s\n", *outputFile)
-	}
-
-	if len(errors) == 0 {
-		fmt.Println("✓ PGN file is valid!")
-		os.Exit(0)
-	}
-
-	// Print found errors
-	fmt.Printf("✗ Found %d errors in PGN file:\n\n", len(errors))
-	for _, err := range errors {
-		fmt.Println(err)
-	}
-	os.Exit(1)
-}
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"pgn_check/pgn"
+)
+
+// Version is set at build time using ldflags
+var Version = "dev"
+
+func main() {
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "fmt":
+			runFormat(os.Args[2:])
+			return
+		case "dedup":
+			runDedup(os.Args[2:])
+			return
+		case "stats":
+			runStats(os.Args[2:])
+			return
+		case "extract":
+			runExtract(os.Args[2:])
+			return
+		case "search":
+			runSearch(os.Args[2:])
+			return
+		case "split":
+			runSplit(os.Args[2:])
+			return
+		case "merge":
+			runMerge(os.Args[2:])
+			return
+		case "export":
+			runExport(os.Args[2:])
+			return
+		case "import":
+			runImport(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		case "lsp":
+			runLSP(os.Args[2:])
+			return
+		case "watch":
+			runWatch(os.Args[2:])
+			return
+		}
+	}
+
+	// Flag definitions
+	outputFile := flag.String("o", "", "Output file with corrections applied")
+	showDiff := flag.Bool("diff", false, "Print a unified diff of the corrections instead of writing a file")
+	writeInPlace := flag.Bool("write", false, "Apply corrections to the input file in place")
+	backup := flag.Bool("backup", false, "Keep a .bak copy of the original file when using -write")
+	aliasFile := flag.String("aliases", "", "Player alias file used to normalize White and Black names")
+	nagStyle := flag.String("nag", "keep", "Annotation style of the output: keep, numeric ($5) or symbolic (!?)")
+	openingTags := flag.Bool("eco", false, "Check ECO tags against the opening played, add missing ECO, Opening and Variation tags and fix wrong ECO tags")
+	maxErrors := flag.Int("max-errors", 0, "Stop validating after this many errors (0: no limit)")
+	failOn := flag.String("fail-on", "error", "Lowest severity failing the validation: error, warning, info or never")
+	maxWarnings := flag.Int("max-warnings", -1, "Fail the validation with more than this many warnings (-1: no limit)")
+	cacheDir := flag.String("cache", "", "Directory caching validation results, so that unchanged files and games are not validated again")
+	progressMode := flag.String("progress", "auto", "Progress display for files over 1 MB: auto (when standard output is a terminal), always, never or json (events on standard error)")
+	version := flag.Bool("version", false, "Show version information")
+	versionShort := flag.Bool("v", false, "Show version information")
+	flag.Parse()
+
+	// Show version if requested
+	if *version || *versionShort {
+		fmt.Printf("pgn_check version %s\n", Version)
+		fmt.Println("Author: Nazario D'Apote <nazario.dapote@gmail.com>")
+		fmt.Println("License: MIT")
+		os.Exit(0)
+	}
+
+	// Check arguments
+	if flag.NArg() < 1 {
+		fmt.Println("Usage: pgn_check [-o output.pgn | -diff | -write [-backup]] [-aliases aliases.txt] [-nag keep|numeric|symbolic] [-eco] [-max-errors N] [-fail-on error|warning|info|never] [-max-warnings N] [-cache directory] [-progress auto|always|never|json] [-v|--version] <file.pgn>")
+		fmt.Println("       pgn_check fmt [-o output.pgn] [-aliases aliases.txt] [-nag keep|numeric|symbolic] <file.pgn>")
+		fmt.Println("       pgn_check dedup [-o output.pgn] <file.pgn> [file.pgn...]")
+		fmt.Println("       pgn_check stats [-json] [-progress auto|always|never|json] <file.pgn> [file.pgn...]")
+		fmt.Println("       pgn_check extract [-o output.pgn] [-fmt] <query> <file.pgn> [file.pgn...]")
+		fmt.Println("       pgn_check search [-fen FEN] [-material KRPvKR] [-variations] <file.pgn> [file.pgn...]")
+		fmt.Println("       pgn_check split [-by event|player|month|count] [-n 1000] [-dir directory] [-fmt] <file.pgn> [file.pgn...]")
+		fmt.Println("       pgn_check merge [-o output.pgn] [-fmt] <file.pgn> [file.pgn...]")
+		fmt.Println("       pgn_check export [-format json|ndjson|csv] [-moves moves.csv] [-o output] <file.pgn> [file.pgn...]")
+		fmt.Println("       pgn_check import [-from auto|json|epd|moves] [-lang en|de|it|fr] [-fail-on error|warning|info|never] [-o output.pgn] <file>")
+		fmt.Println("       pgn_check serve [-addr :8080] [-max-body MB] [-timeout 1m] [-concurrency N]")
+		fmt.Println("       pgn_check lsp [-aliases aliases.txt] [-nag keep|numeric|symbolic] [-eco]")
+		fmt.Println("       pgn_check watch [-poll] [-interval 1s] [-aliases aliases.txt] [-eco] <directory>")
+		fmt.Println("Example: pgn_check game.pgn")
+		fmt.Println("         pgn_check -o corrected.pgn game.pgn")
+		fmt.Println("         pgn_check -diff game.pgn")
+		fmt.Println("         pgn_check -write -backup game.pgn")
+		fmt.Println("         pgn_check -aliases players.txt -o corrected.pgn game.pgn")
+		fmt.Println("         pgn_check -nag numeric -o corrected.pgn game.pgn")
+		fmt.Println("         pgn_check -eco -o corrected.pgn game.pgn")
+		fmt.Println("         pgn_check -cache ~/.cache/pgn_check games.pgn")
+		fmt.Println("         pgn_check -fail-on warning -max-warnings 10 game.pgn")
+		fmt.Println("         pgn_check fmt game.pgn")
+		fmt.Println("         pgn_check --version")
+		os.Exit(exitUsage)
+	}
+
+	if (*outputFile != "" && (*showDiff || *writeInPlace)) || (*showDiff && *writeInPlace) {
+		fatalf(exitUsage, "Error: -o, -diff and -write cannot be used together\n")
+	}
+	if *backup && !*writeInPlace {
+		fatalf(exitUsage, "Error: -backup requires -write\n")
+	}
+
+	filename := flag.Arg(0)
+
+	// Check if file exists
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		fatalf(exitIO, "Error: file '%s' not found\n", filename)
+	}
+
+	// Validate PGN file
+	style, err := pgn.ParseAnnotationStyle(*nagStyle)
+	if err != nil {
+		fatalf(exitUsage, "Error: %v\n", err)
+	}
+
+	progress, err := newProgress(*progressMode)
+	if err != nil {
+		fatalf(exitUsage, "Error: %v\n", err)
+	}
+
+	policy, err := parseFailPolicy(*failOn, *maxWarnings)
+	if err != nil {
+		fatalf(exitUsage, "Error: %v\n", err)
+	}
+
+	validator := pgn.NewPGNValidator()
+	validator.SetProgress(progress)
+	validator.SetAnnotationStyle(style)
+	validator.SetOpeningTags(*openingTags)
+	if *aliasFile != "" {
+		if err := validator.LoadAliases(*aliasFile); err != nil {
+			fatalf(exitIO, "Error loading aliases: %v\n", err)
+		}
+	}
+	if *cacheDir != "" {
+		cache, err := pgn.OpenCache(*cacheDir)
+		if err != nil {
+			fatalf(exitIO, "Error: %v\n", err)
+		}
+		validator.SetCache(cache)
+	}
+	// Errors are printed as they are found; with -diff they go to standard error so the diff
+	// can be piped
+	var report io.Writer = os.Stdout
+	if *showDiff {
+		report = os.Stderr
+	}
+	errorCount := 0
+	counts := map[pgn.Severity]int{}
+	ioFailure := false
+	_, err = validator.ValidateFileWith(filename, pgn.ValidateOptions{
+		MaxErrors: *maxErrors,
+		OnError: func(e pgn.ValidationError) {
+			errorCount++
+			counts[e.Severity]++
+			ioFailure = ioFailure || e.Rule == pgn.RuleFile
+			fmt.Fprintln(report, e)
+		},
+	})
+	stopped := err == pgn.ErrTooManyErrors
+
+	// If -o specified, save corrected file
+	if *outputFile != "" {
+		if err := validator.WriteCorrectedFile(filename, *outputFile); err != nil {
+			fatalf(exitIO, "Error writing corrected file: %v\n", err)
+		}
+		fmt.Printf("✓ Corrected file saved to: %s\n", *outputFile)
+	}
+
+	// If -write specified, replace the input file with its corrected version
+	if *writeInPlace {
+		if err := validator.CorrectFileInPlace(filename, *backup); err != nil {
+			fatalf(exitIO, "Error correcting file: %v\n", err)
+		}
+		fmt.Printf("✓ Corrections applied to: %s\n", filename)
+		if *backup {
+			fmt.Printf("✓ Original file saved to: %s.bak\n", filename)
+		}
+	}
+
+	// If -diff specified, print the corrections as a unified diff
+	if *showDiff {
+		if _, err := validator.WriteCorrectionDiff(filename, os.Stdout); err != nil {
+			fatalf(exitIO, "Error computing diff: %v\n", err)
+		}
+	}
+
+	if errorCount == 0 {
+		if !*showDiff {
+			fmt.Println("✓ PGN file is valid!")
+		}
+		os.Exit(exitOK)
+	}
+
+	// Print the summary of the errors; the exit code follows -fail-on and -max-warnings
+	code, failure := policy.exitCode(counts)
+	if ioFailure {
+		code = exitIO
+	}
+	switch {
+	case stopped:
+		fmt.Fprintf(report, "\n✗ Stopped after %d messages in PGN file (-max-errors)\n", errorCount)
+	case code == exitOK:
+		fmt.Fprintf(report, "\n✓ PGN file is valid (%s)\n", pgn.DescribeCounts(counts))
+	case code == exitWarnings:
+		fmt.Fprintf(report, "\n✗ %s (failing with %s)\n", pgn.DescribeCounts(counts), failure)
+	default:
+		fmt.Fprintf(report, "\n✗ Found %d messages in PGN file (%s)\n", errorCount, pgn.DescribeCounts(counts))
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,831 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// StartFEN is the FEN of the standard chess starting position
+const StartFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// Color is the side to move or the owner of a piece
+type Color int8
+
+const (
+	White Color = iota
+	Black
+)
+
+// Other returns the opposite color
+func (c Color) Other() Color {
+	return c ^ 1
+}
+
+// String returns "White" or "Black"
+func (c Color) String() string {
+	if c == White {
+		return "White"
+	}
+	return "Black"
+}
+
+// PieceType is a kind of chess piece regardless of its color
+type PieceType int8
+
+const (
+	NoPieceType PieceType = iota
+	Pawn
+	Knight
+	Bishop
+	Rook
+	Queen
+	King
+)
+
+// pieceLetters maps piece types to their SAN letters (pawns have none)
+var pieceLetters = [...]string{"", "", "N", "B", "R", "Q", "K"}
+
+// Piece is a colored piece: the piece type in the low bits, the color in bit 3
+type Piece int8
+
+// NoPiece marks an empty square
+const NoPiece Piece = 0
+
+// NewPiece builds a piece from its color and type
+func NewPiece(c Color, t PieceType) Piece {
+	return Piece(t) | Piece(c)<<3
+}
+
+// Type returns the piece type
+func (p Piece) Type() PieceType {
+	return PieceType(p & 7)
+}
+
+// Color returns the piece color
+func (p Piece) Color() Color {
+	return Color(p >> 3)
+}
+
+// Square is a board index from 0 (a1) to 63 (h8)
+type Square int8
+
+// NoSquare marks the absence of a square (e.g. no en passant target)
+const NoSquare Square = -1
+
+func newSquare(file, rank int) Square {
+	return Square(rank*8 + file)
+}
+
+// File returns the file index (0 = a)
+func (s Square) File() int {
+	return int(s) & 7
+}
+
+// Rank returns the rank index (0 = rank 1)
+func (s Square) Rank() int {
+	return int(s) >> 3
+}
+
+func (s Square) String() string {
+	if s == NoSquare {
+		return "-"
+	}
+	return string([]byte{byte('a' + s.File()), byte('1' + s.Rank())})
+}
+
+// parseSquare parses a square in algebraic notation (e.g. "e4")
+func parseSquare(s string) (Square, bool) {
+	if len(s) != 2 || s[0] < 'a' || s[0] > 'h' || s[1] < '1' || s[1] > '8' {
+		return NoSquare, false
+	}
+	return newSquare(int(s[0]-'a'), int(s[1]-'1')), true
+}
+
+// Castling rights bits
+const (
+	castleWhiteKing uint8 = 1 << iota
+	castleWhiteQueen
+	castleBlackKing
+	castleBlackQueen
+)
+
+// Move is a move from one square to another, with an optional promotion piece
+type Move struct {
+	From      Square
+	To        Square
+	Promotion PieceType
+}
+
+// UCI returns the move in UCI long algebraic notation (e.g. "e2e4", "e7e8q")
+func (m Move) UCI() string {
+	s := m.From.String() + m.To.String()
+	if m.Promotion != NoPieceType {
+		s += strings.ToLower(pieceLetters[m.Promotion])
+	}
+	return s
+}
+
+// Position is a chess position with side to move, castling rights and counters
+type Position struct {
+	board          [64]Piece
+	Turn           Color
+	castling       uint8
+	epSquare       Square
+	HalfmoveClock  int
+	FullmoveNumber int
+}
+
+// Movement offsets as (file, rank) deltas
+var (
+	knightOffsets = [8][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingOffsets   = [8][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+	bishopDirs    = [4][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+	rookDirs      = [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+)
+
+// NewStartPosition returns the standard starting position
+func NewStartPosition() *Position {
+	pos, _ := ParseFEN(StartFEN)
+	return pos
+}
+
+// ParseFEN parses a position in Forsyth-Edwards Notation
+func ParseFEN(fen string) (*Position, error) {
+	fields := strings.Fields(fen)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("FEN must have at least 4 fields, found %d", len(fields))
+	}
+
+	pos := &Position{epSquare: NoSquare, FullmoveNumber: 1}
+
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return nil, fmt.Errorf("FEN board must have 8 ranks, found %d", len(ranks))
+	}
+	for i, rankText := range ranks {
+		rank := 7 - i
+		file := 0
+		for _, char := range rankText {
+			if char >= '1' && char <= '8' {
+				file += int(char - '0')
+				continue
+			}
+			color := White
+			if char >= 'a' && char <= 'z' {
+				color = Black
+			}
+			pieceType := NoPieceType
+			switch char {
+			case 'P', 'p':
+				pieceType = Pawn
+			case 'N', 'n':
+				pieceType = Knight
+			case 'B', 'b':
+				pieceType = Bishop
+			case 'R', 'r':
+				pieceType = Rook
+			case 'Q', 'q':
+				pieceType = Queen
+			case 'K', 'k':
+				pieceType = King
+			default:
+				return nil, fmt.Errorf("invalid piece '%c' in FEN", char)
+			}
+			if file > 7 {
+				return nil, fmt.Errorf("too many squares in FEN rank %d", rank+1)
+			}
+			pos.board[newSquare(file, rank)] = NewPiece(color, pieceType)
+			file++
+		}
+		if file != 8 {
+			return nil, fmt.Errorf("FEN rank %d has %d squares instead of 8", rank+1, file)
+		}
+	}
+
+	switch fields[1] {
+	case "w":
+		pos.Turn = White
+	case "b":
+		pos.Turn = Black
+	default:
+		return nil, fmt.Errorf("invalid side to move '%s' in FEN", fields[1])
+	}
+
+	if fields[2] != "-" {
+		for _, char := range fields[2] {
+			switch char {
+			case 'K':
+				pos.castling |= castleWhiteKing
+			case 'Q':
+				pos.castling |= castleWhiteQueen
+			case 'k':
+				pos.castling |= castleBlackKing
+			case 'q':
+				pos.castling |= castleBlackQueen
+			default:
+				return nil, fmt.Errorf("invalid castling rights '%s' in FEN", fields[2])
+			}
+		}
+	}
+
+	if fields[3] != "-" {
+		sq, ok := parseSquare(fields[3])
+		if !ok {
+			return nil, fmt.Errorf("invalid en passant square '%s' in FEN", fields[3])
+		}
+		pos.epSquare = sq
+	}
+
+	if len(fields) > 4 {
+		n, err := strconv.Atoi(fields[4])
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid halfmove clock '%s' in FEN", fields[4])
+		}
+		pos.HalfmoveClock = n
+	}
+	if len(fields) > 5 {
+		n, err := strconv.Atoi(fields[5])
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid fullmove number '%s' in FEN", fields[5])
+		}
+		pos.FullmoveNumber = n
+	}
+
+	if pos.kingSquare(White) == NoSquare || pos.kingSquare(Black) == NoSquare {
+		return nil, fmt.Errorf("FEN must contain one king per side")
+	}
+
+	return pos, nil
+}
+
+// FEN returns the position in Forsyth-Edwards Notation
+func (p *Position) FEN() string {
+	var sb strings.Builder
+	for rank := 7; rank >= 0; rank-- {
+		empty := 0
+		for file := 0; file < 8; file++ {
+			piece := p.board[newSquare(file, rank)]
+			if piece == NoPiece {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				sb.WriteByte(byte('0' + empty))
+				empty = 0
+			}
+			letter := pieceLetters[piece.Type()]
+			if piece.Type() == Pawn {
+				letter = "P"
+			}
+			if piece.Color() == Black {
+				letter = strings.ToLower(letter)
+			}
+			sb.WriteString(letter)
+		}
+		if empty > 0 {
+			sb.WriteByte(byte('0' + empty))
+		}
+		if rank > 0 {
+			sb.WriteByte('/')
+		}
+	}
+
+	if p.Turn == White {
+		sb.WriteString(" w ")
+	} else {
+		sb.WriteString(" b ")
+	}
+
+	castling := ""
+	if p.castling&castleWhiteKing != 0 {
+		castling += "K"
+	}
+	if p.castling&castleWhiteQueen != 0 {
+		castling += "Q"
+	}
+	if p.castling&castleBlackKing != 0 {
+		castling += "k"
+	}
+	if p.castling&castleBlackQueen != 0 {
+		castling += "q"
+	}
+	if castling == "" {
+		castling = "-"
+	}
+	sb.WriteString(castling)
+
+	fmt.Fprintf(&sb, " %s %d %d", p.epSquare, p.HalfmoveClock, p.FullmoveNumber)
+	return sb.String()
+}
+
+// PieceAt returns the piece on a square
+func (p *Position) PieceAt(sq Square) Piece {
+	return p.board[sq]
+}
+
+// Ply returns the number of half-moves played since the start of the game
+func (p *Position) Ply() int {
+	return (p.FullmoveNumber-1)*2 + int(p.Turn)
+}
+
+func (p *Position) kingSquare(c Color) Square {
+	king := NewPiece(c, King)
+	for sq := Square(0); sq < 64; sq++ {
+		if p.board[sq] == king {
+			return sq
+		}
+	}
+	return NoSquare
+}
+
+// offsetSquare returns the square at the given delta from sq, if on the board
+func offsetSquare(sq Square, df, dr int) (Square, bool) {
+	file, rank := sq.File()+df, sq.Rank()+dr
+	if file < 0 || file > 7 || rank < 0 || rank > 7 {
+		return NoSquare, false
+	}
+	return newSquare(file, rank), true
+}
+
+// isAttacked reports whether sq is attacked by any piece of color by
+func (p *Position) isAttacked(sq Square, by Color) bool {
+	// Pawns attack diagonally forward, so look one rank behind the target
+	pawnRank := -1
+	if by == Black {
+		pawnRank = 1
+	}
+	for _, df := range [2]int{-1, 1} {
+		if from, ok := offsetSquare(sq, df, pawnRank); ok && p.board[from] == NewPiece(by, Pawn) {
+			return true
+		}
+	}
+
+	for _, off := range knightOffsets {
+		if from, ok := offsetSquare(sq, off[0], off[1]); ok && p.board[from] == NewPiece(by, Knight) {
+			return true
+		}
+	}
+
+	for _, off := range kingOffsets {
+		if from, ok := offsetSquare(sq, off[0], off[1]); ok && p.board[from] == NewPiece(by, King) {
+			return true
+		}
+	}
+
+	if p.slidingAttack(sq, by, bishopDirs[:], Bishop) || p.slidingAttack(sq, by, rookDirs[:], Rook) {
+		return true
+	}
+
+	return false
+}
+
+// slidingAttack looks along dirs for a slider of the given type (or a queen)
+func (p *Position) slidingAttack(sq Square, by Color, dirs [][2]int, slider PieceType) bool {
+	for _, dir := range dirs {
+		cur := sq
+		for {
+			next, ok := offsetSquare(cur, dir[0], dir[1])
+			if !ok {
+				break
+			}
+			piece := p.board[next]
+			if piece != NoPiece {
+				if piece.Color() == by && (piece.Type() == slider || piece.Type() == Queen) {
+					return true
+				}
+				break
+			}
+			cur = next
+		}
+	}
+	return false
+}
+
+// InCheck reports whether the side to move is in check
+func (p *Position) InCheck() bool {
+	king := p.kingSquare(p.Turn)
+	return king != NoSquare && p.isAttacked(king, p.Turn.Other())
+}
+
+// pseudoLegalMoves generates moves without checking whether the king is left in check
+func (p *Position) pseudoLegalMoves() []Move {
+	moves := make([]Move, 0, 48)
+	us := p.Turn
+
+	for from := Square(0); from < 64; from++ {
+		piece := p.board[from]
+		if piece == NoPiece || piece.Color() != us {
+			continue
+		}
+
+		switch piece.Type() {
+		case Pawn:
+			moves = p.appendPawnMoves(moves, from)
+		case Knight:
+			moves = p.appendStepMoves(moves, from, knightOffsets[:])
+		case King:
+			moves = p.appendStepMoves(moves, from, kingOffsets[:])
+			moves = p.appendCastlingMoves(moves, from)
+		case Bishop:
+			moves = p.appendSlidingMoves(moves, from, bishopDirs[:])
+		case Rook:
+			moves = p.appendSlidingMoves(moves, from, rookDirs[:])
+		case Queen:
+			moves = p.appendSlidingMoves(moves, from, bishopDirs[:])
+			moves = p.appendSlidingMoves(moves, from, rookDirs[:])
+		}
+	}
+
+	return moves
+}
+
+func (p *Position) appendPawnMoves(moves []Move, from Square) []Move {
+	us := p.Turn
+	dir, startRank, lastRank := 1, 1, 7
+	if us == Black {
+		dir, startRank, lastRank = -1, 6, 0
+	}
+
+	appendPawnMove := func(to Square) {
+		if to.Rank() == lastRank {
+			for _, promo := range [4]PieceType{Queen, Rook, Bishop, Knight} {
+				moves = append(moves, Move{From: from, To: to, Promotion: promo})
+			}
+			return
+		}
+		moves = append(moves, Move{From: from, To: to})
+	}
+
+	if to, ok := offsetSquare(from, 0, dir); ok && p.board[to] == NoPiece {
+		appendPawnMove(to)
+		if from.Rank() == startRank {
+			if to2, ok := offsetSquare(from, 0, 2*dir); ok && p.board[to2] == NoPiece {
+				moves = append(moves, Move{From: from, To: to2})
+			}
+		}
+	}
+
+	for _, df := range [2]int{-1, 1} {
+		to, ok := offsetSquare(from, df, dir)
+		if !ok {
+			continue
+		}
+		target := p.board[to]
+		if (target != NoPiece && target.Color() != us) || to == p.epSquare {
+			appendPawnMove(to)
+		}
+	}
+
+	return moves
+}
+
+func (p *Position) appendStepMoves(moves []Move, from Square, offsets [][2]int) []Move {
+	for _, off := range offsets {
+		to, ok := offsetSquare(from, off[0], off[1])
+		if !ok {
+			continue
+		}
+		if target := p.board[to]; target == NoPiece || target.Color() != p.Turn {
+			moves = append(moves, Move{From: from, To: to})
+		}
+	}
+	return moves
+}
+
+func (p *Position) appendSlidingMoves(moves []Move, from Square, dirs [][2]int) []Move {
+	for _, dir := range dirs {
+		cur := from
+		for {
+			to, ok := offsetSquare(cur, dir[0], dir[1])
+			if !ok {
+				break
+			}
+			target := p.board[to]
+			if target != NoPiece {
+				if target.Color() != p.Turn {
+					moves = append(moves, Move{From: from, To: to})
+				}
+				break
+			}
+			moves = append(moves, Move{From: from, To: to})
+			cur = to
+		}
+	}
+	return moves
+}
+
+func (p *Position) appendCastlingMoves(moves []Move, from Square) []Move {
+	us, them := p.Turn, p.Turn.Other()
+	backRank := 0
+	kingSide, queenSide := castleWhiteKing, castleWhiteQueen
+	if us == Black {
+		backRank = 7
+		kingSide, queenSide = castleBlackKing, castleBlackQueen
+	}
+	if from != newSquare(4, backRank) || p.isAttacked(from, them) {
+		return moves
+	}
+
+	rook := NewPiece(us, Rook)
+	if p.castling&kingSide != 0 && p.board[newSquare(7, backRank)] == rook &&
+		p.board[newSquare(5, backRank)] == NoPiece && p.board[newSquare(6, backRank)] == NoPiece &&
+		!p.isAttacked(newSquare(5, backRank), them) && !p.isAttacked(newSquare(6, backRank), them) {
+		moves = append(moves, Move{From: from, To: newSquare(6, backRank)})
+	}
+	if p.castling&queenSide != 0 && p.board[newSquare(0, backRank)] == rook &&
+		p.board[newSquare(1, backRank)] == NoPiece && p.board[newSquare(2, backRank)] == NoPiece &&
+		p.board[newSquare(3, backRank)] == NoPiece &&
+		!p.isAttacked(newSquare(3, backRank), them) && !p.isAttacked(newSquare(2, backRank), them) {
+		moves = append(moves, Move{From: from, To: newSquare(2, backRank)})
+	}
+
+	return moves
+}
+
+// LegalMoves returns all legal moves for the side to move
+func (p *Position) LegalMoves() []Move {
+	pseudo := p.pseudoLegalMoves()
+	legal := pseudo[:0]
+	for _, m := range pseudo {
+		if p.isLegal(m) {
+			legal = append(legal, m)
+		}
+	}
+	return legal
+}
+
+// isLegal reports whether a pseudo-legal move leaves the own king out of check
+func (p *Position) isLegal(m Move) bool {
+	next := p.Play(m)
+	king := next.kingSquare(p.Turn)
+	return king != NoSquare && !next.isAttacked(king, next.Turn)
+}
+
+// Play returns the position reached after making move m. The move is assumed to be legal.
+func (p *Position) Play(m Move) *Position {
+	next := *p
+	piece := next.board[m.From]
+	captured := next.board[m.To]
+
+	next.board[m.To] = piece
+	next.board[m.From] = NoPiece
+	next.epSquare = NoSquare
+
+	switch piece.Type() {
+	case Pawn:
+		// En passant capture removes the pawn behind the target square
+		if m.To == p.epSquare && captured == NoPiece && m.From.File() != m.To.File() {
+			next.board[newSquare(m.To.File(), m.From.Rank())] = NoPiece
+			captured = NewPiece(p.Turn.Other(), Pawn)
+		}
+		if m.Promotion != NoPieceType {
+			next.board[m.To] = NewPiece(p.Turn, m.Promotion)
+		}
+		// Record the en passant square only when an enemy pawn could use it
+		if diff := m.To.Rank() - m.From.Rank(); diff == 2 || diff == -2 {
+			for _, df := range [2]int{-1, 1} {
+				if sq, ok := offsetSquare(m.To, df, 0); ok && next.board[sq] == NewPiece(p.Turn.Other(), Pawn) {
+					next.epSquare = newSquare(m.From.File(), (m.From.Rank()+m.To.Rank())/2)
+				}
+			}
+		}
+	case King:
+		// Castling also moves the rook
+		if diff := m.To.File() - m.From.File(); diff == 2 || diff == -2 {
+			rank := m.From.Rank()
+			rookFrom, rookTo := newSquare(7, rank), newSquare(5, rank)
+			if diff < 0 {
+				rookFrom, rookTo = newSquare(0, rank), newSquare(3, rank)
+			}
+			next.board[rookTo] = next.board[rookFrom]
+			next.board[rookFrom] = NoPiece
+		}
+		if p.Turn == White {
+			next.castling &^= castleWhiteKing | castleWhiteQueen
+		} else {
+			next.castling &^= castleBlackKing | castleBlackQueen
+		}
+	}
+
+	// Moving from or capturing on a rook corner removes the matching right
+	for _, sq := range [2]Square{m.From, m.To} {
+		switch sq {
+		case newSquare(0, 0):
+			next.castling &^= castleWhiteQueen
+		case newSquare(7, 0):
+			next.castling &^= castleWhiteKing
+		case newSquare(0, 7):
+			next.castling &^= castleBlackQueen
+		case newSquare(7, 7):
+			next.castling &^= castleBlackKing
+		}
+	}
+
+	if piece.Type() == Pawn || captured != NoPiece {
+		next.HalfmoveClock = 0
+	} else {
+		next.HalfmoveClock++
+	}
+	if p.Turn == Black {
+		next.FullmoveNumber++
+	}
+	next.Turn = p.Turn.Other()
+
+	return &next
+}
+
+// ParseSAN resolves a move in Standard Algebraic Notation against the legal moves of the position
+func (p *Position) ParseSAN(san string) (Move, error) {
+	s := strings.TrimRight(san, "!?+#")
+
+	// Only candidate moves matching the notation are checked for legality
+	pseudo := p.pseudoLegalMoves()
+
+	// Castling, accepting both letter O and digit zero
+	switch s {
+	case "O-O", "0-0", "O-O-O", "0-0-0":
+		file := 6
+		if len(s) == 5 {
+			file = 2
+		}
+		for _, m := range pseudo {
+			if p.board[m.From].Type() == King && m.From.File() == 4 && m.To.File() == file && p.isLegal(m) {
+				return m, nil
+			}
+		}
+		return Move{}, fmt.Errorf("illegal move '%s'", san)
+	}
+
+	pieceType := Pawn
+	if s != "" {
+		switch s[0] {
+		case 'N':
+			pieceType = Knight
+		case 'B':
+			pieceType = Bishop
+		case 'R':
+			pieceType = Rook
+		case 'Q':
+			pieceType = Queen
+		case 'K':
+			pieceType = King
+		}
+		if pieceType != Pawn {
+			s = s[1:]
+		}
+	}
+
+	promotion := NoPieceType
+	if i := strings.IndexByte(s, '='); i >= 0 {
+		if i+2 != len(s) {
+			return Move{}, fmt.Errorf("invalid promotion in '%s'", san)
+		}
+		promotion = promotionPiece(s[i+1])
+		if promotion == NoPieceType {
+			return Move{}, fmt.Errorf("invalid promotion in '%s'", san)
+		}
+		s = s[:i]
+	} else if pieceType == Pawn && len(s) > 2 {
+		// Promotion without '=' (e.g. "e8Q")
+		if promo := promotionPiece(s[len(s)-1]); promo != NoPieceType {
+			promotion = promo
+			s = s[:len(s)-1]
+		}
+	}
+
+	s = strings.Replace(s, "x", "", 1)
+	if len(s) < 2 {
+		return Move{}, fmt.Errorf("invalid move notation '%s'", san)
+	}
+
+	to, ok := parseSquare(s[len(s)-2:])
+	if !ok {
+		return Move{}, fmt.Errorf("invalid move notation '%s'", san)
+	}
+
+	fromFile, fromRank := -1, -1
+	for _, char := range s[:len(s)-2] {
+		switch {
+		case char >= 'a' && char <= 'h':
+			fromFile = int(char - 'a')
+		case char >= '1' && char <= '8':
+			fromRank = int(char - '1')
+		default:
+			return Move{}, fmt.Errorf("invalid move notation '%s'", san)
+		}
+	}
+
+	var found []Move
+	for _, m := range pseudo {
+		if m.To != to || p.board[m.From].Type() != pieceType || m.Promotion != promotion {
+			continue
+		}
+		if fromFile >= 0 && m.From.File() != fromFile {
+			continue
+		}
+		if fromRank >= 0 && m.From.Rank() != fromRank {
+			continue
+		}
+		if !p.isLegal(m) {
+			continue
+		}
+		found = append(found, m)
+	}
+
+	switch len(found) {
+	case 0:
+		return Move{}, fmt.Errorf("illegal move '%s'", san)
+	case 1:
+		return found[0], nil
+	default:
+		return Move{}, fmt.Errorf("ambiguous move '%s'", san)
+	}
+}
+
+// promotionPiece maps a SAN promotion letter to a piece type
+func promotionPiece(letter byte) PieceType {
+	switch letter {
+	case 'Q':
+		return Queen
+	case 'R':
+		return Rook
+	case 'B':
+		return Bishop
+	case 'N':
+		return Knight
+	}
+	return NoPieceType
+}
+
+// SAN returns the canonical Standard Algebraic Notation of a legal move
+func (p *Position) SAN(m Move) string {
+	piece := p.board[m.From]
+	var sb strings.Builder
+
+	if piece.Type() == King && (m.To.File()-m.From.File() == 2 || m.From.File()-m.To.File() == 2) {
+		if m.To.File() == 6 {
+			sb.WriteString("O-O")
+		} else {
+			sb.WriteString("O-O-O")
+		}
+	} else if piece.Type() == Pawn {
+		if m.From.File() != m.To.File() {
+			sb.WriteByte(byte('a' + m.From.File()))
+			sb.WriteByte('x')
+		}
+		sb.WriteString(m.To.String())
+		if m.Promotion != NoPieceType {
+			sb.WriteByte('=')
+			sb.WriteString(pieceLetters[m.Promotion])
+		}
+	} else {
+		sb.WriteString(pieceLetters[piece.Type()])
+
+		// Disambiguate between pieces of the same type reaching the same square
+		ambiguous, sameFile, sameRank := false, false, false
+		for _, other := range p.LegalMoves() {
+			if other.To != m.To || other.From == m.From || p.board[other.From] != piece {
+				continue
+			}
+			ambiguous = true
+			if other.From.File() == m.From.File() {
+				sameFile = true
+			}
+			if other.From.Rank() == m.From.Rank() {
+				sameRank = true
+			}
+		}
+		if ambiguous {
+			if !sameFile {
+				sb.WriteByte(byte('a' + m.From.File()))
+			} else if !sameRank {
+				sb.WriteByte(byte('1' + m.From.Rank()))
+			} else {
+				sb.WriteString(m.From.String())
+			}
+		}
+
+		if p.board[m.To] != NoPiece {
+			sb.WriteByte('x')
+		}
+		sb.WriteString(m.To.String())
+	}
+
+	next := p.Play(m)
+	if next.InCheck() {
+		if len(next.LegalMoves()) == 0 {
+			sb.WriteByte('#')
+		} else {
+			sb.WriteByte('+')
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,118 @@
+package pgn
+
+import "testing"
+
+// perft counts the leaf nodes of the legal move tree to the given depth
+func perft(pos *Position, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	nodes := 0
+	for _, m := range pos.LegalMoves() {
+		nodes += perft(pos.Play(m), depth-1)
+	}
+	return nodes
+}
+
+func TestPerft(t *testing.T) {
+	tests := []struct {
+		fen   string
+		depth int
+		nodes int
+	}{
+		{StartFEN, 3, 8902},
+		{"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", 2, 2039},
+		{"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", 3, 2812},
+		{"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", 2, 1486},
+	}
+
+	for _, tt := range tests {
+		pos, err := ParseFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("ParseFEN(%q) failed: %v", tt.fen, err)
+		}
+		if got := perft(pos, tt.depth); got != tt.nodes {
+			t.Errorf("perft(%q, %d) = %d, expected %d", tt.fen, tt.depth, got, tt.nodes)
+		}
+	}
+}
+
+func TestFENRoundTrip(t *testing.T) {
+	fens := []string{
+		StartFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/8/8/8/8/8/8/K6k b - - 12 40",
+	}
+	for _, fen := range fens {
+		pos, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf("ParseFEN(%q) failed: %v", fen, err)
+		}
+		if got := pos.FEN(); got != fen {
+			t.Errorf("FEN round trip: expected %q, got %q", fen, got)
+		}
+	}
+
+	invalid := []string{"", "8/8/8 w - -", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1", "8/8/8/8/8/8/8/8 w - - 0 1"}
+	for _, fen := range invalid {
+		if _, err := ParseFEN(fen); err == nil {
+			t.Errorf("Expected error for FEN %q", fen)
+		}
+	}
+}
+
+func TestParseAndFormatSAN(t *testing.T) {
+	pos := NewStartPosition()
+	moves := []struct {
+		input     string
+		canonical string
+		uci       string
+	}{
+		{"e4", "e4", "e2e4"},
+		{"e5", "e5", "e7e5"},
+		{"Nf3", "Nf3", "g1f3"},
+		{"Nc6", "Nc6", "b8c6"},
+		{"Bb5", "Bb5", "f1b5"},
+		{"a6", "a6", "a7a6"},
+		{"Bxc6", "Bxc6", "b5c6"},
+		{"dc6", "dxc6", "d7c6"},
+		{"0-0", "O-O", "e1g1"},
+		{"Qd6", "Qd6", "d8d6"},
+		{"Nc3", "Nc3", "b1c3"},
+		{"Bg4", "Bg4", "c8g4"},
+		{"h3", "h3", "h2h3"},
+		{"O-O-O", "O-O-O", "e8c8"},
+	}
+
+	for _, tt := range moves {
+		m, err := pos.ParseSAN(tt.input)
+		if err != nil {
+			t.Fatalf("ParseSAN(%q) failed: %v", tt.input, err)
+		}
+		if got := pos.SAN(m); got != tt.canonical {
+			t.Errorf("SAN for %q: expected %q, got %q", tt.input, tt.canonical, got)
+		}
+		if got := m.UCI(); got != tt.uci {
+			t.Errorf("UCI for %q: expected %q, got %q", tt.input, tt.uci, got)
+		}
+		pos = pos.Play(m)
+	}
+
+	// Disambiguation, promotion and mate
+	pos, _ = ParseFEN("7k/1P4pp/8/8/8/8/8/R3R1K1 w - - 0 1")
+	if m, err := pos.ParseSAN("Rad1"); err != nil || pos.SAN(m) != "Rad1" {
+		t.Errorf("Expected Rad1 to be parsed and kept, got %v (%v)", pos.SAN(m), err)
+	}
+	if _, err := pos.ParseSAN("Rd1"); err == nil {
+		t.Error("Expected ambiguous move error for Rd1")
+	}
+	if m, err := pos.ParseSAN("b8Q"); err != nil || pos.SAN(m) != "b8=Q#" {
+		t.Errorf("Expected b8Q to become b8=Q#, got %v (%v)", pos.SAN(m), err)
+	}
+	if m, err := pos.ParseSAN("Re8"); err != nil || pos.SAN(m) != "Re8#" {
+		t.Errorf("Expected Re8 to be mate, got %v (%v)", pos.SAN(m), err)
+	}
+	if _, err := pos.ParseSAN("Nf3"); err == nil {
+		t.Error("Expected illegal move error for Nf3")
+	}
+}
@@ -0,0 +1,291 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RuleSetVersion identifies the checks of the validator. It is part of every cache key and
+// must change whenever a check changes, so that results cached by other versions are not used.
+const RuleSetVersion = 5
+
+// Cache stores validation results in a directory, keyed by the hash of the content validated
+// and the rule set version. A file whose content was validated before is not validated
+// again; in a file that changed, only new or modified games are.
+//
+// The directory holds one entry per file content, <key[:2]>/<key>.json, and in paths/ the
+// key of the last content validated for each file path, to find the games of its previous
+// version.
+type Cache struct {
+	dir string
+}
+
+// cacheEntry is the validation of a file content
+type cacheEntry struct {
+	Errors []ValidationError            `json:"errors"` // in the order reported
+	Games  map[string][]ValidationError `json:"games"`  // errors of each game by game key, lines relative to the game
+}
+
+// gameResults holds the game errors used and found while validating a file with the cache
+type gameResults struct {
+	keyPrefix string                       // hashed before each game, see gameKey
+	previous  map[string][]ValidationError // from the last validation of the file
+	current   map[string][]ValidationError // games of the file being validated
+}
+
+// OpenCache returns the cache stored in dir, creating the directory if needed
+func OpenCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(filepath.Join(dir, "paths"), 0755); err != nil {
+		return nil, fmt.Errorf("cannot create cache directory: %v", err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// SetCache sets the cache used by ValidateFile and ValidateFileWith, nil for none (the default)
+func (v *PGNValidator) SetCache(cache *Cache) {
+	v.cache = cache
+}
+
+// cacheKeyPrefix returns what is hashed before the content in cache keys of the given kind:
+// the rule set version and the settings that change the results of the checks (aliases, ECO)
+func (v *PGNValidator) cacheKeyPrefix(kind string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "pgn_check rules %d %s\n", RuleSetVersion, kind)
+	aliases := make([]string, 0, len(v.aliases))
+	for alias, name := range v.aliases {
+		aliases = append(aliases, alias+"="+name)
+	}
+	sort.Strings(aliases)
+	for _, alias := range aliases {
+		fmt.Fprintf(&sb, "alias %s\n", alias)
+	}
+	if v.openingTags {
+		sb.WriteString("eco\n")
+	}
+	return sb.String()
+}
+
+// hexSum returns the hash of h in hexadecimal
+func hexSum(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// gameKey returns the cache key of a game, from its lines without the blank lines following
+// it, which depend on the next game
+func (g *gameResults) gameKey(game *Game) string {
+	lines := game.Lines
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	h := sha256.New()
+	io.WriteString(h, g.keyPrefix)
+	for _, line := range lines {
+		io.WriteString(h, line)
+		io.WriteString(h, "\n")
+	}
+	return hexSum(h)
+}
+
+// validateCached validates a file, using the results of its content or of its games when
+// they were validated before, and stores the results
+func (v *PGNValidator) validateCached(file *os.File, opts ValidateOptions) ([]ValidationError, error) {
+	h := sha256.New()
+	io.WriteString(h, v.cacheKeyPrefix("file"))
+	_, err := io.Copy(h, file)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		v.errors = []ValidationError{{Line: 0, Message: fmt.Sprintf("Error reading file: %v", err), Rule: RuleFile}}
+		return v.reportErrors(opts), nil
+	}
+	key := hexSum(h)
+
+	// Unchanged content: the games need not be read, unless opts.Visit wants them
+	if entry, ok := v.cache.load(key); ok && opts.Visit == nil {
+		return v.reportCachedErrors(entry.Errors, opts)
+	}
+
+	path, err := filepath.Abs(file.Name())
+	if err != nil {
+		path = file.Name()
+	}
+	v.games = &gameResults{keyPrefix: v.cacheKeyPrefix("game"), current: make(map[string][]ValidationError)}
+	if previous, ok := v.cache.load(v.cache.lastKey(path)); ok {
+		v.games.previous = previous.Games
+	}
+	defer func() { v.games = nil }()
+
+	// Collect the errors as they are reported, in the order to store them
+	var all []ValidationError
+	onError := opts.OnError
+	opts.OnError = func(e ValidationError) {
+		all = append(all, e)
+		if onError != nil {
+			onError(e)
+		}
+	}
+	errors, err := v.validate(context.Background(), file, opts)
+
+	// Incomplete results, e.g. after MaxErrors, are not stored
+	if err == nil {
+		v.cache.store(key, path, cacheEntry{Errors: all, Games: v.games.current})
+	}
+	if onError != nil {
+		return errors, err
+	}
+	sortErrors(all)
+	return all, err
+}
+
+// reportCachedErrors reports cached errors as validate would have, stopping at opts.MaxErrors
+func (v *PGNValidator) reportCachedErrors(errors []ValidationError, opts ValidateOptions) ([]ValidationError, error) {
+	var err error
+	if opts.MaxErrors > 0 && len(errors) >= opts.MaxErrors {
+		errors, err = errors[:opts.MaxErrors], ErrTooManyErrors
+	}
+	v.errors = append(make([]ValidationError, 0, len(errors)), errors...)
+	if opts.OnError == nil {
+		sortErrors(v.errors)
+	}
+	return v.reportErrors(opts), err
+}
+
+// replayCachedGame adds the cached errors of a game validated before, and records its
+// players for the checks across games. It reports whether the game was found in the cache.
+func (v *PGNValidator) replayCachedGame(game *Game) bool {
+	if v.games == nil {
+		return false
+	}
+	key := v.games.gameKey(game)
+	errors, ok := v.games.current[key]
+	if !ok {
+		errors, ok = v.games.previous[key]
+	}
+	if !ok {
+		return false
+	}
+
+	v.games.current[key] = errors
+	for _, e := range errors {
+		e.Line += game.StartLine
+		v.errors = append(v.errors, e)
+	}
+	// The players are found as validateTag finds them, in the tag section only
+	for i, line := range game.Lines[:game.MovetextStart] {
+		matches := tagPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		if name := strings.ToLower(matches[1]); name == "white" || name == "black" {
+			v.recordPlayer(v.normalizePlayerName(matches[2]), game.StartLine+i)
+		}
+	}
+	return true
+}
+
+// cacheGame remembers the errors found in a game, if the file is validated with the cache
+func (v *PGNValidator) cacheGame(game *Game, errors []ValidationError) {
+	if v.games == nil {
+		return
+	}
+	relative := make([]ValidationError, len(errors))
+	for i, e := range errors {
+		e.Line -= game.StartLine
+		relative[i] = e
+	}
+	v.games.current[v.games.gameKey(game)] = relative
+}
+
+// entryPath returns the file of the entry with the given key
+func (c *Cache) entryPath(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".json")
+}
+
+// pathIndex returns the file holding the last key validated for a file path
+func (c *Cache) pathIndex(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	return filepath.Join(c.dir, "paths", hex.EncodeToString(sum[:]))
+}
+
+// lastKey returns the key of the last content validated for a file path, "" if none
+func (c *Cache) lastKey(path string) string {
+	data, err := os.ReadFile(c.pathIndex(path))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// load returns the entry with the given key. Missing and unreadable entries are not found.
+func (c *Cache) load(key string) (*cacheEntry, bool) {
+	if len(key) < 2 {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.entryPath(key))
+	if err != nil {
+		return nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// store saves an entry and makes it the last one of a file path. The cache is only an
+// optimization, so failures to write it are ignored.
+func (c *Cache) store(key, path string, entry cacheEntry) {
+	if entry.Errors == nil {
+		entry.Errors = []ValidationError{}
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if writeFileAtomic(c.entryPath(key), data) == nil {
+		writeFileAtomic(c.pathIndex(path), []byte(key+"\n"))
+	}
+}
+
+// writeFileAtomic writes a file through a temporary file renamed over it, so that concurrent
+// readers never see a partial file
+func writeFileAtomic(filename string, data []byte) error {
+	dir := filepath.Dir(filename)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package pgn
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const cacheGame1 = `[Event "Test"]
+[Date "2024-01-15"]
+[White "Carlsen, Magnus"]
+[Black "Nakamura,  Hikaru"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 1-0
+
+`
+
+const cacheGame2 = `[Event "Test"]
+[Date "2024.01.16"]
+[White "Carlsen, M"]
+[Black "Caruana, Fabiano"]
+[Result "*"]
+
+1. d4 d5 2. c4 0-0 *
+
+`
+
+// markCachedGames changes the messages of the game errors stored for a file, to tell the
+// errors replayed from the cache from the errors found again
+func markCachedGames(t *testing.T, cache *Cache, filename string) {
+	path, _ := filepath.Abs(filename)
+	key := cache.lastKey(path)
+	entry, ok := cache.load(key)
+	if !ok {
+		t.Fatalf("Expected a cache entry for %s", filename)
+	}
+	for gameKey, errors := range entry.Games {
+		for i := range errors {
+			errors[i].Message = "cached: " + errors[i].Message
+		}
+		entry.Games[gameKey] = errors
+	}
+	entry.Errors = nil
+	cache.store(key, path, *entry)
+}
+
+func TestCacheReusesFileResults(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "games.pgn")
+	if err := os.WriteFile(filename, []byte(cacheGame1+cacheGame2), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expected := NewPGNValidator().ValidateFile(filename)
+
+	cache, err := OpenCache(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewPGNValidator()
+	v.SetCache(cache)
+	if errors := v.ValidateFile(filename); !reflect.DeepEqual(errors, expected) {
+		t.Fatalf("Expected %v, got %v", expected, errors)
+	}
+	if errors := v.ValidateFile(filename); !reflect.DeepEqual(errors, expected) {
+		t.Fatalf("Expected %v from the cache, got %v", expected, errors)
+	}
+
+	// Errors streamed from the cache stop at MaxErrors
+	var streamed []ValidationError
+	_, err = v.ValidateFileWith(filename, ValidateOptions{
+		MaxErrors: 2,
+		OnError:   func(e ValidationError) { streamed = append(streamed, e) },
+	})
+	if err != ErrTooManyErrors || len(streamed) != 2 {
+		t.Errorf("Expected 2 errors and ErrTooManyErrors, got %v and %v", streamed, err)
+	}
+
+	// An unchanged file is not validated again
+	markCachedGames(t, cache, filename)
+	if errors := v.ValidateFile(filename); len(errors) != 0 {
+		t.Errorf("Expected the stored file results, got %v", errors)
+	}
+}
+
+func TestCacheValidatesChangedGames(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "games.pgn")
+	if err := os.WriteFile(filename, []byte(cacheGame1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache, err := OpenCache(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewPGNValidator()
+	v.SetCache(cache)
+	v.ValidateFile(filename)
+	markCachedGames(t, cache, filename)
+
+	// Only the game appended is validated, the checks across games see both
+	if err := os.WriteFile(filename, []byte(cacheGame1+cacheGame2), 0644); err != nil {
+		t.Fatal(err)
+	}
+	errors := v.ValidateFile(filename)
+	expected := NewPGNValidator().ValidateFile(filename)
+	if len(errors) != 3 || len(expected) != 3 {
+		t.Fatalf("Expected 3 errors, got %v", errors)
+	}
+	for i, e := range errors {
+		want := expected[i]
+		if e.Line <= 8 {
+			want.Message = "cached: " + want.Message
+		}
+		if e != want {
+			t.Errorf("Expected %v, got %v", want, e)
+		}
+	}
+}
+
+func TestCacheKeys(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "games.pgn")
+	if err := os.WriteFile(filename, []byte(cacheGame1), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache, err := OpenCache(filepath.Join(dir, "cache"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewPGNValidator()
+	v.SetCache(cache)
+	v.ValidateFile(filename)
+
+	// Other aliases give other results, so other keys
+	aliased := NewPGNValidator()
+	aliased.aliases = map[string]string{"nakamura, hikaru": "Nakamura, Hikaru"}
+	if v.cacheKeyPrefix("file") == aliased.cacheKeyPrefix("file") {
+		t.Error("Expected the aliases to change the cache keys")
+	}
+	if !strings.Contains(v.cacheKeyPrefix("game"), fmt.Sprintf("rules %d game", RuleSetVersion)) {
+		t.Errorf("Expected the rule set version in the keys, got %q", v.cacheKeyPrefix("game"))
+	}
+
+	// Corrupt entries are ignored
+	path, _ := filepath.Abs(filename)
+	key := cache.lastKey(path)
+	if err := os.WriteFile(cache.entryPath(key), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expected := NewPGNValidator().ValidateFile(filename)
+	if errors := v.ValidateFile(filename); !reflect.DeepEqual(errors, expected) {
+		t.Errorf("Expected %v, got %v", expected, errors)
+	}
+	data, err := os.ReadFile(cache.entryPath(key))
+	if err != nil || !json.Valid(data) {
+		t.Errorf("Expected the corrupt entry to be replaced, got %q", data)
+	}
+}
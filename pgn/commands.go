@@ -0,0 +1,261 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// commandPattern matches an embedded command in a comment: [%name value]
+	commandPattern = regexp.MustCompile(`\[%(\w+)\s*([^\]]*)\]`)
+
+	// clockPattern matches a clock time: H:MM:SS with optional fractions of a second
+	clockPattern = regexp.MustCompile(`^(\d+):([0-5]\d):([0-5]\d)(\.\d+)?$`)
+
+	// evalPattern matches an evaluation in pawns or centipawns ("0.34", "-120") or a mate
+	// in n moves ("#3", "#-3"), optionally followed by the search depth (",20")
+	evalPattern = regexp.MustCompile(`^([+-]?(\d+(\.\d+)?|\.\d+)|#[+-]?\d+)(,\d+)?$`)
+
+	// squareListPattern matches the colored squares of a [%csl] command ("Gd4")
+	squareListPattern = regexp.MustCompile(`^[RGYB][a-h][1-8]$`)
+
+	// arrowListPattern matches the colored arrows of a [%cal] command ("Re2e4")
+	arrowListPattern = regexp.MustCompile(`^[RGYB][a-h][1-8][a-h][1-8]$`)
+)
+
+// Command is an embedded command of a comment, such as [%clk 0:03:12]
+type Command struct {
+	Name  string `json:"name"`  // command name without "%", e.g. "clk"
+	Value string `json:"value"` // command arguments as written
+	Line  int    `json:"-"`     // line number of the command
+}
+
+// String returns the command as written in a comment
+func (c Command) String() string {
+	return fmt.Sprintf("[%%%s %s]", c.Name, c.Value)
+}
+
+// parseCommands separates the embedded commands of a comment from its text. It returns the
+// remaining text, trimmed, and the commands; firstLine is the line number where the
+// comment starts.
+func parseCommands(comment string, firstLine int) (string, []Command) {
+	matches := commandPattern.FindAllStringSubmatchIndex(comment, -1)
+	if matches == nil {
+		return strings.TrimSpace(comment), nil
+	}
+
+	commands := make([]Command, 0, len(matches))
+	var text strings.Builder
+	end := 0
+	for _, match := range matches {
+		commands = append(commands, Command{
+			Name:  comment[match[2]:match[3]],
+			Value: strings.TrimSpace(comment[match[4]:match[5]]),
+			Line:  firstLine + strings.Count(comment[:match[0]], "\n"),
+		})
+		text.WriteString(comment[end:match[0]])
+		end = match[1]
+	}
+	text.WriteString(comment[end:])
+
+	return strings.Join(strings.Fields(text.String()), " "), commands
+}
+
+// parseClock converts a clock time such as "0:03:12" or "1:02:03.4" to a duration
+func parseClock(value string) (time.Duration, error) {
+	matches := clockPattern.FindStringSubmatch(value)
+	if matches == nil {
+		return 0, fmt.Errorf("expected H:MM:SS")
+	}
+	hours, _ := strconv.Atoi(matches[1])
+	minutes, _ := strconv.Atoi(matches[2])
+	seconds, _ := strconv.Atoi(matches[3])
+	clock := time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	if matches[4] != "" {
+		fraction, _ := strconv.ParseFloat(matches[4], 64)
+		clock += time.Duration(fraction * float64(time.Second))
+	}
+	return clock, nil
+}
+
+// formatClock formats a duration as a clock time H:MM:SS
+func formatClock(d time.Duration) string {
+	seconds := int(d / time.Second)
+	return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+}
+
+// validateCommandSyntax checks the value of the known embedded commands, returning a
+// description of the expected format if it is malformed. Unknown commands are accepted.
+func validateCommandSyntax(c Command) error {
+	switch c.Name {
+	case "clk", "emt", "egt", "mct":
+		_, err := parseClock(c.Value)
+		return err
+
+	case "eval":
+		if !evalPattern.MatchString(c.Value) {
+			return fmt.Errorf("expected an evaluation such as 0.34, -120 or #-3")
+		}
+
+	case "csl", "cal":
+		pattern, example := squareListPattern, "Gd4,Re5"
+		if c.Name == "cal" {
+			pattern, example = arrowListPattern, "Ge2e4,Rd1h5"
+		}
+		for _, item := range strings.Split(c.Value, ",") {
+			if !pattern.MatchString(strings.TrimSpace(item)) {
+				return fmt.Errorf("expected a list such as %s (colors R, G, Y, B)", example)
+			}
+		}
+	}
+	return nil
+}
+
+// timeControlPeriod is one period of a TimeControl tag
+type timeControlPeriod struct {
+	moves     int           // moves to play in the period, 0 for the rest of the game
+	time      time.Duration // time added at the start of the period
+	increment time.Duration // time added after each move
+}
+
+// parseTimeControl parses a TimeControl tag such as "40/7200:3600", "300+2" or
+// "40/5400+30:1800+30". It returns nil for unknown ("?"), untimed ("-"), sandclock ("*180")
+// or malformed values.
+func parseTimeControl(value string) []timeControlPeriod {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "?" || value == "-" {
+		return nil
+	}
+
+	var periods []timeControlPeriod
+	for _, field := range strings.Split(value, ":") {
+		var period timeControlPeriod
+		if moves, rest, found := strings.Cut(field, "/"); found {
+			n, err := strconv.Atoi(moves)
+			if err != nil || n <= 0 {
+				return nil
+			}
+			period.moves, field = n, rest
+		}
+		seconds, increment, hasIncrement := strings.Cut(field, "+")
+		n, err := strconv.Atoi(seconds)
+		if err != nil || n < 0 {
+			return nil
+		}
+		period.time = time.Duration(n) * time.Second
+		if hasIncrement {
+			n, err := strconv.Atoi(increment)
+			if err != nil || n < 0 {
+				return nil
+			}
+			period.increment = time.Duration(n) * time.Second
+		}
+		periods = append(periods, period)
+	}
+	return periods
+}
+
+// timeAdded returns the time a player may gain with their n-th move (counting from 1):
+// the increment of the current period, plus the time of the next period when the move
+// completes a period with a move limit
+func timeAdded(periods []timeControlPeriod, n int) time.Duration {
+	moves := 0
+	for i, period := range periods {
+		if period.moves == 0 || n <= moves+period.moves {
+			added := period.increment
+			if period.moves > 0 && n == moves+period.moves {
+				// Move completing the period: the next period starts (the last one repeats)
+				next := periods[len(periods)-1]
+				if i+1 < len(periods) {
+					next = periods[i+1]
+				}
+				added += next.time
+			}
+			return added
+		}
+		moves += period.moves
+	}
+	// Past the last period with a move limit, which repeats
+	last := periods[len(periods)-1]
+	if (n-moves)%last.moves == 0 {
+		return last.increment + last.time
+	}
+	return last.increment
+}
+
+// validateCommands checks the embedded commands of a game: their syntax in every line of
+// play, and clock times of the mainline against the TimeControl tag
+func (v *PGNValidator) validateCommands(g *Game) {
+	if !hasCommands(g) {
+		return
+	}
+	tree := g.MoveTree()
+
+	checkSyntax := func(commands []Command) {
+		for _, c := range commands {
+			if err := validateCommandSyntax(c); err != nil {
+				v.errors = append(v.errors, ValidationError{
+					Line:     c.Line,
+					Message:  fmt.Sprintf("Warning: Invalid embedded command %s: %v", c, err),
+					Rule:     RuleCommand,
+					Severity: SeverityWarning,
+				})
+			}
+		}
+	}
+	var walk func(moves []*MoveNode)
+	walk = func(moves []*MoveNode) {
+		for _, node := range moves {
+			checkSyntax(node.Commands)
+			for _, variation := range node.Variations {
+				walk(variation)
+			}
+		}
+	}
+	checkSyntax(tree.Commands)
+	walk(tree.Moves)
+
+	// Clock times may only increase by the time the TimeControl adds after a move
+	timeControl := g.Tag("TimeControl")
+	periods := parseTimeControl(timeControl)
+	if periods == nil {
+		return
+	}
+	var clocks [2]time.Duration
+	var known [2]bool
+	var movesPlayed [2]int
+	for _, node := range tree.Moves {
+		side := node.Color()
+		movesPlayed[side]++
+		for _, c := range node.Commands {
+			if c.Name != "clk" {
+				continue
+			}
+			clock, err := parseClock(c.Value)
+			if err != nil {
+				continue
+			}
+			// Clocks are usually rounded to the second
+			limit := clocks[side] + timeAdded(periods, movesPlayed[side]) + time.Second
+			if known[side] && clock > limit {
+				v.errors = append(v.errors, ValidationError{
+					Line: c.Line,
+					Message: fmt.Sprintf("Warning: %s clock increases from %s to %s at move %d (TimeControl '%s')",
+						side, formatClock(clocks[side]), formatClock(clock), node.MoveNumber(), timeControl),
+					Rule:     RuleClock,
+					Severity: SeverityWarning,
+				})
+			}
+			clocks[side], known[side] = clock, true
+		}
+	}
+}
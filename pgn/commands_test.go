@@ -0,0 +1,136 @@
+package pgn
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestValidateCommandSyntax(t *testing.T) {
+	tests := []struct {
+		command Command
+		valid   bool
+	}{
+		{Command{Name: "clk", Value: "0:03:12"}, true},
+		{Command{Name: "clk", Value: "1:02:03.4"}, true},
+		{Command{Name: "clk", Value: "3:12"}, false},
+		{Command{Name: "emt", Value: "0:00:75"}, false},
+		{Command{Name: "eval", Value: "0.34"}, true},
+		{Command{Name: "eval", Value: "-120"}, true},
+		{Command{Name: "eval", Value: "#-3"}, true},
+		{Command{Name: "eval", Value: "+1.25,20"}, true},
+		{Command{Name: "eval", Value: "mate"}, false},
+		{Command{Name: "csl", Value: "Gd4,Re5"}, true},
+		{Command{Name: "csl", Value: "Gd9"}, false},
+		{Command{Name: "cal", Value: "Ge2e4, Rd1h5"}, true},
+		{Command{Name: "cal", Value: "Ge2"}, false},
+		{Command{Name: "custom", Value: "anything"}, true},
+	}
+
+	for _, tt := range tests {
+		if err := validateCommandSyntax(tt.command); (err == nil) != tt.valid {
+			t.Errorf("%s: expected valid=%v, got error %v", tt.command, tt.valid, err)
+		}
+	}
+}
+
+func TestParseCommands(t *testing.T) {
+	text, commands := parseCommands(" Good move [%clk 0:03:12]\n[%eval 0.34] really ", 7)
+	if text != "Good move really" {
+		t.Errorf("Expected text 'Good move really', got '%s'", text)
+	}
+	if len(commands) != 2 || commands[0].Line != 7 || commands[1].Line != 8 || commands[1].Value != "0.34" {
+		t.Errorf("Unexpected commands %v", commands)
+	}
+}
+
+func TestTimeAdded(t *testing.T) {
+	tests := []struct {
+		timeControl string
+		move        int
+		expected    time.Duration
+	}{
+		{"180+2", 10, 2 * time.Second},
+		{"40/7200:3600", 39, 0},
+		{"40/7200:3600", 40, 3600 * time.Second},
+		{"40/5400+30:1800+30", 40, 1830 * time.Second},
+		{"40/5400+30:1800+30", 41, 30 * time.Second},
+		{"40/7200", 80, 7200 * time.Second},
+	}
+
+	for _, tt := range tests {
+		periods := parseTimeControl(tt.timeControl)
+		if periods == nil {
+			t.Fatalf("Cannot parse TimeControl '%s'", tt.timeControl)
+		}
+		if got := timeAdded(periods, tt.move); got != tt.expected {
+			t.Errorf("%s, move %d: expected %v, got %v", tt.timeControl, tt.move, tt.expected, got)
+		}
+	}
+
+	for _, value := range []string{"?", "-", "*180", "abc"} {
+		if parseTimeControl(value) != nil {
+			t.Errorf("Expected no periods for '%s'", value)
+		}
+	}
+}
+
+func TestValidateCommands(t *testing.T) {
+	content := `[Event "Rated Blitz game"]
+[TimeControl "180+2"]
+[Result "*"]
+
+1. e4 { [%clk 0:03:00] [%eval 0.34] } 1... e5 { [%clk 0:03:00] } 2. Nf3 { [%clk 0:03:01] }
+2... Nc6 { [%clk 0:03:10] [%csl Gd4,Xe5] } 3. Bb5 { [%clk 0:2:1] }
+( 3. Bc4 { [%eval abc] } ) *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	validator := NewPGNValidator()
+	errors := validator.ValidateFile(tmpFile)
+
+	expected := []string{
+		"Line 6: Warning: Invalid embedded command [%csl Gd4,Xe5]: expected a list such as Gd4,Re5 (colors R, G, Y, B)",
+		"Line 6: Warning: Invalid embedded command [%clk 0:2:1]: expected H:MM:SS",
+		"Line 6: Warning: Black clock increases from 0:03:00 to 0:03:10 at move 2 (TimeControl '180+2')",
+		"Line 7: Warning: Invalid embedded command [%eval abc]: expected an evaluation such as 0.34, -120 or #-3",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, err := range errors {
+		if err.String() != expected[i] {
+			t.Errorf("Message %d:\n  expected: %s\n  got:      %s", i, expected[i], err.String())
+		}
+	}
+}
+
+func TestCommandLinesInComments(t *testing.T) {
+	// A comment line starting with a command is movetext, not a malformed tag
+	content := `[Event "Rated Blitz game"]
+[White "Carlsen, Magnus"]
+[Black "Nakamura, Hikaru"]
+[Result "*"]
+
+1. e4 { Good move
+[%clk 0:03:00] } *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	cache, err := OpenCache(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := NewPGNValidator()
+	validator.SetCache(cache)
+	// The second validation replays the game from the cache
+	for i := 0; i < 2; i++ {
+		for _, e := range validator.ValidateFile(tmpFile) {
+			if e.Rule == RuleTag || e.Severity == SeverityError {
+				t.Errorf("Unexpected error %v", e)
+			}
+		}
+	}
+}
@@ -0,0 +1,505 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// pieceLanguages maps the piece letters of localized SAN to English letters
+var pieceLanguages = map[string]map[rune]rune{
+	"en": {'K': 'K', 'Q': 'Q', 'R': 'R', 'B': 'B', 'N': 'N'},
+	"de": {'K': 'K', 'D': 'Q', 'T': 'R', 'L': 'B', 'S': 'N'}, // König, Dame, Turm, Läufer, Springer
+	"it": {'R': 'K', 'D': 'Q', 'T': 'R', 'A': 'B', 'C': 'N'}, // Re, Donna, Torre, Alfiere, Cavallo
+	"fr": {'R': 'K', 'D': 'Q', 'T': 'R', 'F': 'B', 'C': 'N'}, // Roi, Dame, Tour, Fou, Cavalier
+}
+
+// figurinePieces maps figurine symbols of both colors to English piece letters; pawns map to
+// 0 and are dropped
+var figurinePieces = map[rune]rune{
+	'♔': 'K', '♕': 'Q', '♖': 'R', '♗': 'B', '♘': 'N', '♙': 0,
+	'♚': 'K', '♛': 'Q', '♜': 'R', '♝': 'B', '♞': 'N', '♟': 0,
+}
+
+// uciMovePattern matches a move in UCI notation, e.g. e2e4 or e7e8q
+// Groups: (1) from square, (2) to square, (3) promotion piece
+var uciMovePattern = regexp.MustCompile(`^([a-h][1-8])([a-h][1-8])([qrbn])?$`)
+
+// lanMovePattern matches a move in long algebraic notation (after translation to English
+// letters), e.g. e2-e4, Ng1-f3, Bb5xc6 or e7-e8=Q
+// Groups: (1) piece, (2) from square, (3) to square, (4) promotion piece
+var lanMovePattern = regexp.MustCompile(`^([KQRBN])?([a-h][1-8])[-x:]([a-h][1-8])=?([QRBNqrbn])?$`)
+
+// MoveConverter translates moves written in UCI, long algebraic, figurine or localized SAN
+// notation to moves of a position
+type MoveConverter struct {
+	letters map[rune]rune
+}
+
+// NewMoveConverter creates a converter reading piece letters of a language: en, de, it or fr.
+// UCI, long algebraic and figurine notations are recognized in every language.
+func NewMoveConverter(language string) (*MoveConverter, error) {
+	letters, ok := pieceLanguages[language]
+	if !ok {
+		return nil, fmt.Errorf("unknown notation language '%s' (expected en, de, it or fr)", language)
+	}
+	return &MoveConverter{letters: letters}, nil
+}
+
+// translate replaces figurines and localized piece letters with English piece letters
+func (c *MoveConverter) translate(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		if letter, ok := figurinePieces[r]; ok {
+			if letter != 0 {
+				sb.WriteRune(letter)
+			}
+			continue
+		}
+		if letter, ok := c.letters[r]; ok {
+			r = letter
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Convert returns the legal move of pos written as text
+func (c *MoveConverter) Convert(pos *Position, text string) (Move, error) {
+	s := strings.TrimRight(text, "!?+#")
+
+	if m := uciMovePattern.FindStringSubmatch(s); m != nil {
+		return findMove(pos, m[1], m[2], m[3], NoPieceType)
+	}
+
+	english := c.translate(s)
+	if m := lanMovePattern.FindStringSubmatch(english); m != nil {
+		pieceType := Pawn
+		if m[1] != "" {
+			pieceType = promotionPiece(m[1][0])
+			if m[1] == "K" {
+				pieceType = King
+			}
+		}
+		return findMove(pos, m[2], m[3], m[4], pieceType)
+	}
+
+	return pos.ParseSAN(english)
+}
+
+// findMove returns the legal move between two squares. promotion is a piece letter in either
+// case, "" if none; pieceType is the moving piece or NoPieceType if not given.
+func findMove(pos *Position, from, to, promotion string, pieceType PieceType) (Move, error) {
+	fromSquare, _ := parseSquare(from)
+	toSquare, _ := parseSquare(to)
+	promoted := NoPieceType
+	if promotion != "" {
+		promoted = promotionPiece(strings.ToUpper(promotion)[0])
+	}
+
+	if pieceType != NoPieceType && pos.board[fromSquare].Type() != pieceType {
+		return Move{}, fmt.Errorf("no %s on %s", pieceNames[pieceType], from)
+	}
+	for _, m := range pos.LegalMoves() {
+		if m.From != fromSquare || m.To != toSquare {
+			continue
+		}
+		if m.Promotion != promoted {
+			if promoted == NoPieceType {
+				return Move{}, fmt.Errorf("missing promotion piece in %s%s", from, to)
+			}
+			continue
+		}
+		return m, nil
+	}
+	return Move{}, fmt.Errorf("illegal move from %s to %s", from, to)
+}
+
+// pieceNames names the piece types in error messages
+var pieceNames = map[PieceType]string{
+	Pawn: "pawn", Knight: "knight", Bishop: "bishop", Rook: "rook", Queen: "queen", King: "king",
+}
+
+// convertLine tracks one line of play (mainline or variation) while converting moves
+type convertLine struct {
+	pos     *Position // position before the next move, nil once a move cannot be translated
+	prev    *Position // position before the last move, used to start variations
+	skipped []string  // moves following a move that cannot be translated
+}
+
+// moveLabel returns the move number of the next move of a position, e.g. "12." or "12..."
+func moveLabel(pos *Position) string {
+	if pos.Turn == White {
+		return fmt.Sprintf("%d.", pos.FullmoveNumber)
+	}
+	return fmt.Sprintf("%d...", pos.FullmoveNumber)
+}
+
+// convertMovetext converts the moves of movetext lines, starting at line firstLine, to SAN
+// movetext words. Comments, NAGs and variations are kept. The first move of a line of play
+// that cannot be translated is reported with its position; it and the moves following it
+// are kept in a comment. It returns the words and the game termination marker.
+func (c *MoveConverter) convertMovetext(lines []string, firstLine int, start *Position) ([]string, string, []ValidationError) {
+	var words []string
+	var problems []ValidationError
+	result := ""
+
+	line := &convertLine{pos: start}
+	var stack []*convertLine
+	endLine := func() {
+		if len(line.skipped) > 0 {
+			words = append(words, "{Untranslated: "+strings.Join(line.skipped, " ")+"}")
+		}
+	}
+
+	for _, tok := range tokenizeMovetext(lines, firstLine) {
+		switch tok.Kind {
+		case TokenComment:
+			words = append(words, "{"+strings.TrimSpace(tok.Text)+"}")
+		case TokenLineComment:
+			words = append(words, "{"+strings.TrimSpace(tok.Text)+"}")
+		case TokenNAG:
+			if line.pos == nil {
+				line.skipped = append(line.skipped, tok.Text)
+				continue
+			}
+			words = append(words, tok.Text)
+		case TokenVariationStart:
+			stack = append(stack, line)
+			line = &convertLine{pos: line.prev}
+			words = append(words, "(")
+		case TokenVariationEnd:
+			if len(stack) == 0 {
+				continue
+			}
+			endLine()
+			line = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			words = append(words, ")")
+		case TokenResult:
+			if len(stack) == 0 {
+				result = tok.Text
+			}
+		case TokenMove, TokenUnknown:
+			if line.pos == nil {
+				line.skipped = append(line.skipped, tok.Text)
+				continue
+			}
+			text, suffix := splitSuffixAnnotation(tok.Text)
+			m, err := c.Convert(line.pos, text)
+			if err != nil {
+				problems = append(problems, ValidationError{
+					Line:    tok.Line,
+					Message: fmt.Sprintf("Cannot translate move '%s' at %s: %v (position: %s)", tok.Text, moveLabel(line.pos), err, line.pos.FEN()),
+					Rule:    RuleMoveNotation,
+				})
+				line.pos, line.prev, line.skipped = nil, nil, []string{tok.Text}
+				continue
+			}
+			words = append(words, line.pos.SAN(m)+suffix)
+			line.prev, line.pos = line.pos, line.pos.Play(m)
+		}
+	}
+	for len(stack) > 0 {
+		endLine()
+		line = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		words = append(words, ")")
+	}
+	endLine()
+
+	return words, result, problems
+}
+
+// parseUCIPosition parses a UCI "position" command: "position startpos [moves ...]" or
+// "position fen <FEN> [moves ...]". It returns the position given by a FEN (nil for startpos)
+// and the moves.
+func parseUCIPosition(command string) (*Position, string, error) {
+	fields := strings.Fields(command)
+	setup, moves, _ := strings.Cut(strings.Join(fields[1:], " "), "moves")
+	setupFields := strings.Fields(setup)
+	switch {
+	case len(setupFields) == 1 && setupFields[0] == "startpos":
+		return nil, moves, nil
+	case len(setupFields) > 1 && setupFields[0] == "fen":
+		pos, err := ParseFEN(strings.Join(setupFields[1:], " "))
+		if err != nil {
+			return nil, "", fmt.Errorf("Invalid FEN in UCI position command: %v", err)
+		}
+		return pos, moves, nil
+	}
+	return nil, "", fmt.Errorf("Invalid UCI position command '%s' (expected 'position startpos moves ...' or 'position fen <FEN> moves ...')", command)
+}
+
+// paragraphReader reads a text file as paragraphs of non-blank lines
+type paragraphReader struct {
+	reader *bufio.Reader
+	line   int // number of the last line read
+	err    error
+}
+
+// next returns the lines of the next paragraph and the line number of its first line; no
+// lines at the end of the input
+func (p *paragraphReader) next() ([]string, int) {
+	var lines []string
+	start := 0
+	for p.err == nil {
+		text, err := p.reader.ReadString('\n')
+		if err != nil {
+			p.err = err
+			if text == "" {
+				break
+			}
+		}
+		p.line++
+		text = strings.TrimRight(text, "\r\n")
+		if p.line == 1 {
+			text = strings.TrimPrefix(text, "\uFEFF")
+		}
+		if strings.TrimSpace(text) == "" {
+			if len(lines) > 0 {
+				break
+			}
+			continue
+		}
+		if len(lines) == 0 {
+			start = p.line
+		}
+		lines = append(lines, text)
+	}
+	return lines, start
+}
+
+// readError returns the read error, nil at the end of the input
+func (p *paragraphReader) readError() error {
+	if p.err == io.EOF {
+		return nil
+	}
+	return p.err
+}
+
+// convertedGame builds a game from tag lines and movetext words
+func convertedGame(tags []string, words []string) *Game {
+	lines := append(append([]string{}, tags...), "", strings.Join(words, " "))
+	game := &Game{StartLine: 1, Lines: lines}
+	game.parseTags()
+	return game
+}
+
+// ImportMoveLists reads games written as move lists and writes them to w in PGN export format.
+// Games are separated by blank lines; each may start with tag pairs and its moves may be in
+// UCI, long algebraic, figurine or localized SAN notation, with or without move numbers. A UCI
+// "position startpos moves ..." or "position fen <FEN> moves ..." command is also accepted.
+// It returns the number of games written and the moves that could not be translated.
+func (v *PGNValidator) ImportMoveLists(r io.Reader, w io.Writer, converter *MoveConverter) (int, []ValidationError, error) {
+	input := &paragraphReader{reader: bufio.NewReaderSize(r, 1024*1024)}
+	writer := bufio.NewWriterSize(w, 1024*1024)
+	written := 0
+	var problems []ValidationError
+
+	var tags []string
+	for {
+		lines, start := input.next()
+		if len(lines) == 0 {
+			break
+		}
+
+		// Tag pairs, followed by the movetext in the same paragraph or the next one
+		i := 0
+		for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), "[") {
+			tags = append(tags, strings.TrimSpace(lines[i]))
+			i++
+		}
+		if i == len(lines) {
+			continue
+		}
+		movetext, firstLine := lines[i:], start+i
+
+		game := convertedGame(tags, nil)
+		startPos := gameStartPosition(game)
+		if startPos == nil {
+			problems = append(problems, ValidationError{Line: firstLine, Message: fmt.Sprintf("Invalid FEN tag '%s', moves cannot be translated", game.Tag("FEN")), Rule: RuleMoveNotation})
+		}
+		if fields := strings.Fields(movetext[0]); len(fields) > 0 && fields[0] == "position" {
+			pos, moves, err := parseUCIPosition(movetext[0])
+			if err != nil {
+				problems = append(problems, ValidationError{Line: firstLine, Message: err.Error(), Rule: RuleMoveNotation})
+				startPos, moves = nil, ""
+			} else if pos != nil {
+				startPos = pos
+				tags = append(tags, "[SetUp \"1\"]", fmt.Sprintf("[FEN \"%s\"]", pos.FEN()))
+			}
+			movetext = append([]string{moves}, movetext[1:]...)
+		}
+
+		words, result, gameProblems := converter.convertMovetext(movetext, firstLine, startPos)
+		problems = append(problems, gameProblems...)
+		if result == "" {
+			result = "*"
+		}
+		words = append(words, result)
+
+		if _, err := writer.WriteString(v.formatGame(convertedGame(tags, words))); err != nil {
+			return written, problems, fmt.Errorf("error writing: %v", err)
+		}
+		written++
+		tags = nil
+	}
+	if len(tags) > 0 {
+		// Tag pairs without movetext at the end of the input
+		if _, err := writer.WriteString(v.formatGame(convertedGame(tags, []string{"*"}))); err != nil {
+			return written, problems, fmt.Errorf("error writing: %v", err)
+		}
+		written++
+	}
+
+	if err := input.readError(); err != nil {
+		return written, problems, fmt.Errorf("error reading: %v", err)
+	}
+	return written, problems, writer.Flush()
+}
+
+// splitEPD separates the first n whitespace-separated fields of an EPD record from the rest
+func splitEPD(record string, n int) ([]string, string) {
+	var fields []string
+	rest := strings.TrimSpace(record)
+	for len(fields) < n && rest != "" {
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		fields = append(fields, rest[:end])
+		rest = strings.TrimSpace(rest[end:])
+	}
+	return fields, rest
+}
+
+// epdOperations splits the operations of an EPD record, e.g. `bm Nf3; id "pos 1";`, at the
+// semicolons outside quoted strings
+func epdOperations(text string) []string {
+	var operations []string
+	start, quoted := 0, false
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			quoted = !quoted
+		case ';':
+			if !quoted {
+				operations = append(operations, strings.TrimSpace(text[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(text[start:]); last != "" {
+		operations = append(operations, last)
+	}
+	return operations
+}
+
+// ImportEPD reads a list of positions, one EPD record or FEN per line, and writes a game
+// without moves for each to w in PGN export format. The "id" operation becomes the Event tag,
+// "hmvc" and "fmvn" set the move counters, and the other operations are kept in a comment.
+// Empty lines and lines starting with '#' are skipped. It returns the number of games written
+// and the lines that are not valid positions.
+func (v *PGNValidator) ImportEPD(r io.Reader, w io.Writer) (int, []ValidationError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	writer := bufio.NewWriterSize(w, 1024*1024)
+	written, lineNumber := 0, 0
+	var problems []ValidationError
+
+	for scanner.Scan() {
+		lineNumber++
+		record := strings.TrimSpace(scanner.Text())
+		if lineNumber == 1 {
+			record = strings.TrimPrefix(record, "\uFEFF")
+		}
+		if record == "" || strings.HasPrefix(record, "#") {
+			continue
+		}
+
+		// A FEN has the move counters after the four EPD fields
+		fields, rest := splitEPD(record, 6)
+		counters := "0 1"
+		if len(fields) == 6 && isDigits(fields[4]) && isDigits(fields[5]) {
+			counters = fields[4] + " " + fields[5]
+		} else {
+			fields, rest = splitEPD(record, 4)
+		}
+
+		event := "?"
+		var comments []string
+		for _, operation := range epdOperations(rest) {
+			opcode, operand, _ := strings.Cut(operation, " ")
+			operand = strings.TrimSpace(operand)
+			switch opcode {
+			case "id":
+				if unquoted, err := strconv.Unquote(operand); err == nil {
+					operand = unquoted
+				}
+				event = operand
+			case "hmvc":
+				counters = operand + counters[strings.IndexByte(counters, ' '):]
+			case "fmvn":
+				counters = counters[:strings.IndexByte(counters, ' ')+1] + operand
+			default:
+				comments = append(comments, operation)
+			}
+		}
+
+		pos, err := ParseFEN(strings.Join(fields, " ") + " " + counters)
+		if err != nil {
+			problems = append(problems, ValidationError{
+				Line:    lineNumber,
+				Message: fmt.Sprintf("Invalid position '%s': %v", record, err),
+				Rule:    RuleTag,
+			})
+			continue
+		}
+
+		tags := []string{
+			fmt.Sprintf("[Event \"%s\"]", strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(event)),
+			"[SetUp \"1\"]",
+			fmt.Sprintf("[FEN \"%s\"]", pos.FEN()),
+		}
+		var words []string
+		if len(comments) > 0 {
+			words = append(words, "{"+strings.ReplaceAll(strings.Join(comments, "; "), "}", "")+"}")
+		}
+		words = append(words, "*")
+
+		if _, err := writer.WriteString(v.formatGame(convertedGame(tags, words))); err != nil {
+			return written, problems, fmt.Errorf("error writing: %v", err)
+		}
+		written++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return written, problems, fmt.Errorf("error reading: %v", err)
+	}
+	return written, problems, writer.Flush()
+}
+
+// isDigits reports whether s is a non-empty string of decimal digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
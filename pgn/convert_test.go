@@ -0,0 +1,154 @@
+package pgn
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMoveConverter(t *testing.T) {
+	promotionFEN := "8/4P3/8/8/8/8/k7/7K w - - 0 1"
+	castlingFEN := "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+	tests := []struct {
+		language string
+		fen      string
+		text     string
+		expected string
+	}{
+		{"en", StartFEN, "e2e4", "e4"},
+		{"en", StartFEN, "g1f3", "Nf3"},
+		{"en", StartFEN, "e2-e4", "e4"},
+		{"en", StartFEN, "Ng1-f3", "Nf3"},
+		{"en", StartFEN, "♘f3", "Nf3"},
+		{"en", StartFEN, "♙e4", "e4"},
+		{"en", StartFEN, "Nf3!", "Nf3"},
+		{"de", StartFEN, "Sf3", "Nf3"},
+		{"de", StartFEN, "Sg1-f3", "Nf3"},
+		{"it", StartFEN, "Cf3", "Nf3"},
+		{"fr", StartFEN, "Cc3", "Nc3"},
+		{"en", promotionFEN, "e7e8q", "e8=Q"},
+		{"en", promotionFEN, "e7e8n", "e8=N"},
+		{"en", promotionFEN, "e7-e8=Q", "e8=Q"},
+		{"de", promotionFEN, "e8=D", "e8=Q"},
+		{"de", promotionFEN, "e8T", "e8=R"},
+		{"fr", promotionFEN, "e8=F", "e8=B"},
+		{"en", promotionFEN, "e8=♕", "e8=Q"},
+		{"de", castlingFEN, "0-0-0", "O-O-O"},
+		{"it", castlingFEN, "Rf1", "Kf1"},
+		{"it", castlingFEN, "Td1", "Rd1"},
+		{"fr", castlingFEN, "e1g1", "O-O"},
+		{"en", castlingFEN, "Ke1-g1", "O-O"},
+	}
+
+	for _, tt := range tests {
+		converter, err := NewMoveConverter(tt.language)
+		if err != nil {
+			t.Fatalf("NewMoveConverter(%s) failed: %v", tt.language, err)
+		}
+		pos, err := ParseFEN(tt.fen)
+		if err != nil {
+			t.Fatalf("ParseFEN failed: %v", err)
+		}
+		m, err := converter.Convert(pos, tt.text)
+		if err != nil {
+			t.Errorf("Convert(%s, %q) failed: %v", tt.language, tt.text, err)
+			continue
+		}
+		if got := pos.SAN(m); got != tt.expected {
+			t.Errorf("Convert(%s, %q) = %s, expected %s", tt.language, tt.text, got, tt.expected)
+		}
+	}
+}
+
+func TestMoveConverterErrors(t *testing.T) {
+	converter, _ := NewMoveConverter("en")
+	pos, _ := ParseFEN("8/4P3/8/8/8/8/k7/7K w - - 0 1")
+	for _, text := range []string{"e7e8", "e7e6", "Ne7-e8", "Sf3", "xyz"} {
+		if _, err := converter.Convert(pos, text); err == nil {
+			t.Errorf("Convert(%q) should fail", text)
+		}
+	}
+
+	if _, err := NewMoveConverter("es"); err == nil {
+		t.Error("Unknown language should be rejected")
+	}
+}
+
+func TestImportMoveLists(t *testing.T) {
+	input := `[White "Müller"]
+[Black "Schmidt"]
+
+1. e4 e5 2. Sf3 Sc6 3. Lb5 {Spanisch} a6 (3... Sf6) 1-0
+
+e2e4 c7c5 g1f3
+
+1. e4 e5 2. Ke3 Sc6 3. Lc4
+
+position fen 4k3/8/8/8/8/8/8/4K2R w K - 0 1 moves e1g1 e8d7
+`
+	converter, _ := NewMoveConverter("de")
+	var out bytes.Buffer
+	written, problems, err := NewPGNValidator().ImportMoveLists(strings.NewReader(input), &out, converter)
+	if err != nil {
+		t.Fatalf("ImportMoveLists failed: %v", err)
+	}
+	if written != 4 {
+		t.Errorf("Expected 4 games, got %d", written)
+	}
+
+	pgn := out.String()
+	for _, expected := range []string{
+		"[White \"Müller\"]",
+		"1. e4 e5 2. Nf3 Nc6 3. Bb5 {Spanisch} 3... a6 (3... Nf6) 1-0",
+		"1. e4 c5 2. Nf3 *",
+		"1. e4 e5 {Untranslated: Ke3 Sc6 Lc4} *",
+		"[FEN \"4k3/8/8/8/8/8/8/4K2R w K - 0 1\"]",
+		"1. O-O Kd7 *",
+	} {
+		if !strings.Contains(pgn, expected) {
+			t.Errorf("Output should contain %q:\n%s", expected, pgn)
+		}
+	}
+
+	if len(problems) != 1 {
+		t.Fatalf("Expected 1 untranslatable move, got %v", problems)
+	}
+	if problems[0].Line != 8 || !strings.Contains(problems[0].Message, "'Ke3' at 2.") ||
+		!strings.Contains(problems[0].Message, "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2") {
+		t.Errorf("Unexpected problem: %v", problems[0])
+	}
+}
+
+func TestImportEPD(t *testing.T) {
+	input := `# Test positions
+rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 bm e5; id "opening \"1\"";
+8/8/8/8/8/8/8/k6K w - - 5 40
+8/8/8/8/8/8/8/k6K w - - hmvc 3; fmvn 12;
+8/8/8/8 w - -
+`
+	var out bytes.Buffer
+	written, problems, err := NewPGNValidator().ImportEPD(strings.NewReader(input), &out)
+	if err != nil {
+		t.Fatalf("ImportEPD failed: %v", err)
+	}
+	if written != 3 {
+		t.Errorf("Expected 3 games, got %d", written)
+	}
+
+	pgn := out.String()
+	for _, expected := range []string{
+		`[Event "opening \"1\""]`,
+		`[FEN "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1"]`,
+		"{bm e5} *",
+		`[FEN "8/8/8/8/8/8/8/k6K w - - 5 40"]`,
+		`[FEN "8/8/8/8/8/8/8/k6K w - - 3 12"]`,
+		`[SetUp "1"]`,
+	} {
+		if !strings.Contains(pgn, expected) {
+			t.Errorf("Output should contain %q:\n%s", expected, pgn)
+		}
+	}
+	if len(problems) != 1 || problems[0].Line != 5 {
+		t.Errorf("Expected the invalid position on line 5, got %v", problems)
+	}
+}
@@ -0,0 +1,177 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+)
+
+// DefaultCSVTags are the tag columns of the game table unless others are selected
+var DefaultCSVTags = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result", "WhiteElo", "BlackElo", "ECO"}
+
+// csvMoveColumns are the columns of the move table
+var csvMoveColumns = []string{"game_id", "ply", "san", "uci", "fen", "clock", "eval"}
+
+// CSVExporter writes one row per game to a game table and, optionally, one row per mainline
+// move to a move table. Games are numbered from 1 in the game_id column of both tables.
+type CSVExporter struct {
+	tags        []string
+	games       *csv.Writer
+	moves       *csv.Writer // nil without a move table
+	gamesBuffer *bufio.Writer
+	movesBuffer *bufio.Writer
+	id          int
+}
+
+// NewCSVExporter creates an exporter writing the game table to games and, if moves is not
+// nil, the move table to moves. The game table has a column per tag, then plies and termination.
+func NewCSVExporter(games, moves io.Writer, tags []string) (*CSVExporter, error) {
+	e := &CSVExporter{tags: tags, gamesBuffer: bufio.NewWriterSize(games, 1024*1024)}
+	e.games = csv.NewWriter(e.gamesBuffer)
+	header := append(append([]string{"game_id"}, tags...), "plies", "termination")
+	if err := e.games.Write(header); err != nil {
+		return nil, fmt.Errorf("error writing: %v", err)
+	}
+
+	if moves != nil {
+		e.movesBuffer = bufio.NewWriterSize(moves, 1024*1024)
+		e.moves = csv.NewWriter(e.movesBuffer)
+		if err := e.moves.Write(csvMoveColumns); err != nil {
+			return nil, fmt.Errorf("error writing: %v", err)
+		}
+	}
+	return e, nil
+}
+
+// AddGame writes the rows of a game
+func (e *CSVExporter) AddGame(g *Game) error {
+	e.id++
+	id := fmt.Sprint(e.id)
+
+	row := make([]string, 0, len(e.tags)+3)
+	row = append(row, id)
+	for _, tag := range e.tags {
+		row = append(row, g.Tag(tag))
+	}
+	row = append(row, fmt.Sprint(mainlinePlies(g)), gameTermination(g))
+	if err := e.games.Write(row); err != nil {
+		return fmt.Errorf("error writing: %v", err)
+	}
+
+	if e.moves == nil {
+		return nil
+	}
+	// Replay the mainline only: positions of variations are not needed
+	tree := g.MoveTree()
+	before := tree.Start
+	for _, node := range tree.Moves {
+		san, uci, fen, clock, eval := node.SAN, "", "", "", ""
+		if node.Position != nil && before != nil {
+			san, uci, fen = before.SAN(node.Move), node.Move.UCI(), node.Position.FEN()
+		}
+		for _, c := range node.Commands {
+			switch c.Name {
+			case "clk":
+				clock = c.Value
+			case "eval":
+				eval = c.Value
+			}
+		}
+		if err := e.moves.Write([]string{id, fmt.Sprint(node.Ply + 1), san, uci, fen, clock, eval}); err != nil {
+			return fmt.Errorf("error writing: %v", err)
+		}
+		before = node.Position
+	}
+	return nil
+}
+
+// Flush writes the buffered rows
+func (e *CSVExporter) Flush() error {
+	e.games.Flush()
+	if err := e.games.Error(); err != nil {
+		return fmt.Errorf("error writing: %v", err)
+	}
+	if err := e.gamesBuffer.Flush(); err != nil {
+		return fmt.Errorf("error writing: %v", err)
+	}
+	if e.moves != nil {
+		e.moves.Flush()
+		if err := e.moves.Error(); err != nil {
+			return fmt.Errorf("error writing: %v", err)
+		}
+		if err := e.movesBuffer.Flush(); err != nil {
+			return fmt.Errorf("error writing: %v", err)
+		}
+	}
+	return nil
+}
+
+// gameTermination returns the termination marker at the end of the mainline, or the Result
+// tag if there is none, normalized; "*" if both are missing
+func gameTermination(g *Game) string {
+	depth, termination := 0, ""
+	for _, tok := range tokenizeMovetext(g.Movetext(), g.MovetextLine()) {
+		switch tok.Kind {
+		case TokenVariationStart:
+			depth++
+		case TokenVariationEnd:
+			if depth > 0 {
+				depth--
+			}
+		case TokenResult:
+			if depth == 0 {
+				termination = tok.Text
+			}
+		}
+	}
+	if termination == "" {
+		termination = normalizeResult(g.Tag("Result"))
+	}
+	if termination == "" {
+		termination = "*"
+	}
+	return termination
+}
+
+// WriteCSVFiles writes every game of the input files as CSV, one game at a time so that
+// files of any size can be exported. It returns the number of games written.
+func WriteCSVFiles(inputFiles []string, games, moves io.Writer, tags []string) (int, error) {
+	exporter, err := NewCSVExporter(games, moves, tags)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, filename := range inputFiles {
+		file, err := os.Open(filename)
+		if err != nil {
+			return exporter.id, fmt.Errorf("cannot open input file: %v", err)
+		}
+
+		scanner := NewGameScanner(file)
+		for scanner.Scan() {
+			game := scanner.Game()
+			if !hasContent(game) {
+				continue
+			}
+			if err := exporter.AddGame(game); err != nil {
+				file.Close()
+				return exporter.id, err
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return exporter.id, fmt.Errorf("error reading: %v", err)
+		}
+	}
+
+	return exporter.id, exporter.Flush()
+}
@@ -0,0 +1,58 @@
+package pgn
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestWriteCSVFiles(t *testing.T) {
+	content := `[Event "Blitz, Open"]
+[White "Carlsen, Magnus"]
+[Black "Caruana, Fabiano"]
+[Result "1-0"]
+
+1. e4 { [%clk 0:03:00] [%eval 0.3] } 1... e5 (1... c5 2. Nf3) 2. Qh5 1-0
+
+[Event "Blitz"]
+[Result "*"]
+
+1. d4 Zz9
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	var games, moves bytes.Buffer
+	written, err := WriteCSVFiles([]string{tmpFile}, &games, &moves, []string{"Event", "White", "Black", "ECO"})
+	if err != nil || written != 2 {
+		t.Fatalf("Expected 2 games, got %d (error %v)", written, err)
+	}
+
+	expectedGames := `game_id,Event,White,Black,ECO,plies,termination
+1,"Blitz, Open","Carlsen, Magnus","Caruana, Fabiano",,3,1-0
+2,Blitz,,,,2,*
+`
+	if games.String() != expectedGames {
+		t.Errorf("Unexpected game table:\n%s\nexpected:\n%s", games.String(), expectedGames)
+	}
+
+	expectedMoves := `game_id,ply,san,uci,fen,clock,eval
+1,1,e4,e2e4,rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1,0:03:00,0.3
+1,2,e5,e7e5,rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq - 0 2,,
+1,3,Qh5,d1h5,rnbqkbnr/pppp1ppp/8/4p2Q/4P3/8/PPPP1PPP/RNB1KBNR b KQkq - 1 2,,
+2,1,d4,d2d4,rnbqkbnr/pppppppp/8/8/3P4/8/PPP1PPPP/RNBQKBNR b KQkq - 0 1,,
+2,2,Zz9,,,,
+`
+	if moves.String() != expectedMoves {
+		t.Errorf("Unexpected move table:\n%s\nexpected:\n%s", moves.String(), expectedMoves)
+	}
+
+	// Without a move table only the games are written
+	games.Reset()
+	if _, err := WriteCSVFiles([]string{tmpFile}, &games, nil, DefaultCSVTags); err != nil {
+		t.Fatalf("WriteCSVFiles failed: %v", err)
+	}
+	if !bytes.HasPrefix(games.Bytes(), []byte("game_id,Event,Site,Date,Round,White,Black,Result,WhiteElo,BlackElo,ECO,plies,termination\n")) {
+		t.Errorf("Unexpected header:\n%s", games.String())
+	}
+}
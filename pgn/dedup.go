@@ -0,0 +1,430 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Kinds of duplicate groups, from the strictest to the loosest
+const (
+	DuplicateExact     = "exact duplicates"
+	DuplicateTags      = "same moves, different tags"
+	DuplicateTruncated = "truncated movetext"
+)
+
+// gameRecord holds what duplicate detection needs to know about a game
+type gameRecord struct {
+	file    int
+	line    int
+	white   string
+	black   string
+	date    string
+	round   string
+	result  string
+	players string // normalized player names, "" if unknown
+	moves   string // encoded mainline
+	plies   int
+	tags    int
+	size    int // movetext bytes, counting comments and variations
+}
+
+// DuplicateGroup is a set of games considered copies of the same game
+type DuplicateGroup struct {
+	Kind  string
+	Games []int // indexes of the games, in input order
+	Keep  int   // index of the most complete copy
+}
+
+// Deduplicator finds duplicate games within and across PGN files
+type Deduplicator struct {
+	files     []string
+	games     []gameRecord
+	parent    []int            // union-find forest over games
+	longest   []int            // game with the most plies of the group, by root
+	exact     map[string]int   // moves and key tags -> first game
+	byPlayers map[string][]int // normalized players -> games
+}
+
+// NewDeduplicator creates an empty deduplicator
+func NewDeduplicator() *Deduplicator {
+	return &Deduplicator{
+		exact:     make(map[string]int),
+		byPlayers: make(map[string][]int),
+	}
+}
+
+// hasContent reports whether a game chunk holds tags or movetext
+func hasContent(g *Game) bool {
+	return len(g.Tags) > 0 || strings.TrimSpace(strings.Join(g.Movetext(), "")) != ""
+}
+
+// firstLine returns the line number of the first non-blank line of a game
+func firstLine(g *Game) int {
+	for i, line := range g.Lines {
+		if strings.TrimSpace(line) != "" {
+			return g.StartLine + i
+		}
+	}
+	return g.StartLine
+}
+
+// AddFile reads every game of a PGN file and links it to the duplicates seen so far
+func (d *Deduplicator) AddFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("cannot open input file: %v", err)
+	}
+	defer file.Close()
+
+	d.files = append(d.files, filename)
+	fileIndex := len(d.files) - 1
+
+	scanner := NewGameScanner(file)
+	for scanner.Scan() {
+		game := scanner.Game()
+		if !hasContent(game) {
+			continue
+		}
+
+		moves, plies := mainlineKey(game)
+		record := gameRecord{
+			file:   fileIndex,
+			line:   firstLine(game),
+			white:  strings.TrimSpace(game.Tag("White")),
+			black:  strings.TrimSpace(game.Tag("Black")),
+			date:   strings.TrimSpace(game.Tag("Date")),
+			round:  strings.TrimSpace(game.Tag("Round")),
+			result: strings.TrimSpace(game.Tag("Result")),
+			moves:  moves,
+			plies:  plies,
+			tags:   len(game.Tags),
+			size:   len(strings.Join(game.Movetext(), "\n")),
+		}
+		if white, black := playerKey(record.white), playerKey(record.black); white != "" && black != "" {
+			record.players = white + "|" + black
+		}
+		d.add(record)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading: %v", err)
+	}
+
+	return nil
+}
+
+// add registers a game and links it to the earlier games it duplicates
+func (d *Deduplicator) add(record gameRecord) {
+	index := len(d.games)
+	d.games = append(d.games, record)
+	d.parent = append(d.parent, index)
+	d.longest = append(d.longest, index)
+
+	exactKey := strings.Join([]string{record.moves, record.white, record.black, record.date, record.round, record.result}, "\x00")
+	if other, ok := d.exact[exactKey]; ok {
+		d.union(other, index)
+		return
+	}
+	d.exact[exactKey] = index
+
+	// Near duplicates need the same players, tags compatible with every game of the group
+	// and one movetext equal to or a prefix of the longest movetext of the group. Comparing
+	// with the longest only keeps a short game from joining unrelated longer games; a game
+	// that could belong to several groups joins none.
+	if record.players == "" || record.plies == 0 {
+		return
+	}
+	candidates := d.byPlayers[record.players]
+	d.byPlayers[record.players] = append(candidates, index)
+	conflicts := make(map[int]bool)
+	for _, other := range candidates {
+		if !tagsCompatible(d.games[other], record) {
+			conflicts[d.find(other)] = true
+		}
+	}
+	match := -1
+	for _, other := range candidates {
+		root := d.find(other)
+		if conflicts[root] || root == match {
+			continue
+		}
+		longest := d.games[d.longest[root]]
+		if !strings.HasPrefix(longest.moves, record.moves) && !strings.HasPrefix(record.moves, longest.moves) {
+			continue
+		}
+		if match >= 0 {
+			return
+		}
+		match = root
+	}
+	if match >= 0 {
+		d.union(match, index)
+	}
+}
+
+// tagsCompatible reports whether the tags of two games may describe the same game: the
+// dates may be the same day, the rounds are equal or unknown and the results do not conflict
+func tagsCompatible(a, b gameRecord) bool {
+	return datesCompatible(a.date, b.date) &&
+		(a.round == b.round || unknownTag(a.round) || unknownTag(b.round)) &&
+		(a.result == b.result || unknownTag(a.result) || unknownTag(b.result))
+}
+
+// unknownTag reports whether a tag value stands for an unknown value, e.g. the round "?" or
+// the result "*" of a game still in progress
+func unknownTag(value string) bool {
+	return value == "" || value == "?" || value == "-" || value == "*"
+}
+
+func (d *Deduplicator) find(i int) int {
+	for d.parent[i] != i {
+		d.parent[i] = d.parent[d.parent[i]]
+		i = d.parent[i]
+	}
+	return i
+}
+
+func (d *Deduplicator) union(i, j int) {
+	ri, rj := d.find(i), d.find(j)
+	if ri > rj {
+		ri, rj = rj, ri
+	}
+	if ri == rj {
+		return
+	}
+	d.parent[rj] = ri
+	if d.games[d.longest[rj]].plies > d.games[d.longest[ri]].plies {
+		d.longest[ri] = d.longest[rj]
+	}
+}
+
+// GameCount returns the number of games read so far
+func (d *Deduplicator) GameCount() int {
+	return len(d.games)
+}
+
+// Groups returns the duplicate groups found, ordered by their first game
+func (d *Deduplicator) Groups() []DuplicateGroup {
+	members := make(map[int][]int)
+	for i := range d.games {
+		root := d.find(i)
+		members[root] = append(members[root], i)
+	}
+
+	groups := []DuplicateGroup{}
+	for _, games := range members {
+		if len(games) < 2 {
+			continue
+		}
+
+		group := DuplicateGroup{Kind: DuplicateExact, Games: games, Keep: games[0]}
+		first := d.games[games[0]]
+		for _, i := range games {
+			g := d.games[i]
+			if g.moves != first.moves {
+				group.Kind = DuplicateTruncated
+			} else if group.Kind == DuplicateExact &&
+				(g.white != first.white || g.black != first.black || g.date != first.date ||
+					g.round != first.round || g.result != first.result) {
+				group.Kind = DuplicateTags
+			}
+			if d.moreComplete(g, d.games[group.Keep]) {
+				group.Keep = i
+			}
+		}
+		groups = append(groups, group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Games[0] < groups[j].Games[0]
+	})
+	return groups
+}
+
+// moreComplete reports whether game a is a more complete copy than game b:
+// more moves first, then more tags, then more annotations
+func (d *Deduplicator) moreComplete(a, b gameRecord) bool {
+	if a.plies != b.plies {
+		return a.plies > b.plies
+	}
+	if a.tags != b.tags {
+		return a.tags > b.tags
+	}
+	return a.size > b.size
+}
+
+// Describe returns a one-line description of a game for reports
+func (d *Deduplicator) Describe(index int) string {
+	g := d.games[index]
+	return fmt.Sprintf("%s:%d: %s - %s (%s) %s, %d plies",
+		d.files[g.file], g.line, g.white, g.black, g.date, g.result, g.plies)
+}
+
+// WriteDeduplicated writes all games of the input files to outputFile, keeping only the
+// most complete copy of each duplicate group. The lines of the games are copied unchanged,
+// separated by one blank line and with the line endings of their input file.
+func (d *Deduplicator) WriteDeduplicated(outputFile string) (int, error) {
+	redundant := make(map[int]bool)
+	for _, group := range d.Groups() {
+		for _, i := range group.Games {
+			if i != group.Keep {
+				redundant[i] = true
+			}
+		}
+	}
+
+	outFile, err := os.Create(outputFile)
+	if err != nil {
+		return 0, fmt.Errorf("cannot create output file: %v", err)
+	}
+	defer outFile.Close()
+
+	// Increase writer buffer size to 1MB
+	writer := bufio.NewWriterSize(outFile, 1024*1024)
+	index, written := 0, 0
+
+	for _, filename := range d.files {
+		file, err := os.Open(filename)
+		if err != nil {
+			return written, fmt.Errorf("cannot open input file: %v", err)
+		}
+
+		scanner := NewGameScanner(file)
+		for scanner.Scan() {
+			game := scanner.Game()
+			if !hasContent(game) {
+				continue
+			}
+			index++
+			if redundant[index-1] {
+				continue
+			}
+
+			if err := writeGameLines(writer, game, scanner.LineEnding()); err != nil {
+				file.Close()
+				return written, fmt.Errorf("error writing: %v", err)
+			}
+			written++
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return written, fmt.Errorf("error reading: %v", err)
+		}
+	}
+
+	return written, writer.Flush()
+}
+
+// writeGameLines writes the lines of a game unchanged, without leading and trailing blank
+// lines, followed by one blank line. Every line ends with eol, whatever its line ending in the
+// input.
+func writeGameLines(w *bufio.Writer, g *Game, eol string) error {
+	lines := g.Lines
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for _, line := range lines {
+		if _, err := w.WriteString(line + eol); err != nil {
+			return err
+		}
+	}
+	_, err := w.WriteString(eol)
+	return err
+}
+
+// mainlineKey encodes the mainline moves of a game for duplicate detection, returning the
+// key and the number of plies. Replayed moves take two bytes each; once a move cannot be
+// replayed the remaining moves are kept as text.
+func mainlineKey(g *Game) (string, int) {
+	var sb strings.Builder
+	pos := gameStartPosition(g)
+	depth, plies := 0, 0
+
+	for _, tok := range tokenizeMovetext(g.Movetext(), g.MovetextLine()) {
+		switch tok.Kind {
+		case TokenVariationStart:
+			depth++
+		case TokenVariationEnd:
+			if depth > 0 {
+				depth--
+			}
+		case TokenMove:
+			if depth > 0 {
+				continue
+			}
+			plies++
+			if pos != nil {
+				if m, err := pos.ParseSAN(tok.Text); err == nil {
+					code := int(m.From)<<9 | int(m.To)<<3 | int(m.Promotion)
+					sb.WriteByte(byte(code >> 8))
+					sb.WriteByte(byte(code))
+					pos = pos.Play(m)
+					continue
+				}
+				pos = nil
+				sb.WriteByte(0xff)
+			}
+			core, _ := splitSuffixAnnotation(tok.Text)
+			sb.WriteString(normalizeCastling(strings.TrimRight(core, "+#")))
+			sb.WriteByte(' ')
+		}
+	}
+
+	return sb.String(), plies
+}
+
+// playerKey normalizes a player name to its lowercase surname and first initial, so that
+// "Carlsen, Magnus", "Carlsen,M." and "Magnus Carlsen" compare equal. It returns "" for
+// unknown players.
+func playerKey(name string) string {
+	name = strings.TrimSpace(name)
+	if name == "" || name == "?" || name == "-" {
+		return ""
+	}
+
+	surname, given := name, ""
+	if i := strings.IndexByte(name, ','); i >= 0 {
+		surname, given = name[:i], name[i+1:]
+	} else if fields := strings.Fields(name); len(fields) > 1 {
+		surname, given = fields[len(fields)-1], strings.Join(fields[:len(fields)-1], " ")
+	}
+
+	key := lettersOnly(surname)
+	if initials := []rune(lettersOnly(given)); len(initials) > 0 {
+		key += " " + string(initials[0])
+	}
+	return key
+}
+
+// lettersOnly returns the lowercase letters of s
+func lettersOnly(s string) string {
+	var sb strings.Builder
+	for _, char := range s {
+		if unicode.IsLetter(char) {
+			sb.WriteRune(unicode.ToLower(char))
+		}
+	}
+	return sb.String()
+}
+
+// datesCompatible reports whether two date tags may refer to the same day
+func datesCompatible(a, b string) bool {
+	if !correctDatePattern.MatchString(a) || !correctDatePattern.MatchString(b) {
+		return true
+	}
+	return a == b
+}
@@ -0,0 +1,165 @@
+package pgn
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeduplicator(t *testing.T) {
+	first := `[Event "Test"]
+[Date "2023.01.10"]
+[White "Carlsen, Magnus"]
+[Black "Caruana, Fabiano"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 1-0
+
+[Event "Test"]
+[Date "2023.01.11"]
+[White "Caruana, Fabiano"]
+[Black "Carlsen, Magnus"]
+[Result "1/2-1/2"]
+
+1. d4 Nf6 2. c4 e6 1/2-1/2
+`
+	second := `[Event "Test"]
+[Date "2023.01.10"]
+[White "Carlsen,M"]
+[Black "Caruana,F"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 {A comment} 3. Bb5 a6 1-0
+
+[Event "Test"]
+[Date "2023.01.10"]
+[White "Carlsen, Magnus"]
+[Black "Caruana, Fabiano"]
+[Result "*"]
+
+1. e4 e5 2. Nf3 *
+
+[Event "Other"]
+[Date "2023.01.10"]
+[White "Someone, Else"]
+[Black "Caruana, Fabiano"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 1-0
+`
+	firstFile := createTempFile(t, first)
+	defer os.Remove(firstFile)
+	secondFile := createTempFile(t, second)
+	defer os.Remove(secondFile)
+
+	deduplicator := NewDeduplicator()
+	for _, filename := range []string{firstFile, secondFile} {
+		if err := deduplicator.AddFile(filename); err != nil {
+			t.Fatalf("AddFile failed: %v", err)
+		}
+	}
+
+	if deduplicator.GameCount() != 5 {
+		t.Fatalf("Expected 5 games, got %d", deduplicator.GameCount())
+	}
+
+	groups := deduplicator.Groups()
+	if len(groups) != 1 {
+		t.Fatalf("Expected 1 duplicate group, got %d: %v", len(groups), groups)
+	}
+	group := groups[0]
+	if group.Kind != DuplicateTruncated {
+		t.Errorf("Expected kind %q, got %q", DuplicateTruncated, group.Kind)
+	}
+	if len(group.Games) != 3 || group.Games[0] != 0 || group.Games[1] != 2 || group.Games[2] != 3 {
+		t.Errorf("Expected games 0, 2 and 3 in the group, got %v", group.Games)
+	}
+	// Same length and tags: the copy with the comment is the most complete
+	if group.Keep != 2 {
+		t.Errorf("Expected game 2 to be kept, got %d", group.Keep)
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "dedup.pgn")
+	written, err := deduplicator.WriteDeduplicated(outputFile)
+	if err != nil {
+		t.Fatalf("WriteDeduplicated failed: %v", err)
+	}
+	if written != 3 {
+		t.Errorf("Expected 3 games written, got %d", written)
+	}
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if !strings.Contains(string(data), "{A comment}") || strings.Contains(string(data), `[White "Carlsen, Magnus"]`+"\n"+`[Black "Caruana, Fabiano"]`+"\n"+`[Result "*"]`) {
+		t.Errorf("Unexpected deduplicated content:\n%s", data)
+	}
+}
+
+// dedupGroups returns the duplicate groups of a PGN content
+func dedupGroups(t *testing.T, content string) []DuplicateGroup {
+	t.Helper()
+	filename := createTempFile(t, content)
+	defer os.Remove(filename)
+	deduplicator := NewDeduplicator()
+	if err := deduplicator.AddFile(filename); err != nil {
+		t.Fatalf("AddFile failed: %v", err)
+	}
+	return deduplicator.Groups()
+}
+
+// dedupGame returns a game between the same players on the same day
+func dedupGame(round, result, moves string) string {
+	return fmt.Sprintf("[Event \"Test\"]\n[Date \"2023.01.10\"]\n[Round \"%s\"]\n[White \"Carlsen, Magnus\"]\n"+
+		"[Black \"Caruana, Fabiano\"]\n[Result \"%s\"]\n\n%s %s\n\n", round, result, moves, result)
+}
+
+func TestDeduplicatorNearDuplicateRules(t *testing.T) {
+	// Games of different rounds, or with conflicting results, are different games even if
+	// one movetext is a prefix of the other
+	for _, content := range []string{
+		dedupGame("1.1", "1/2-1/2", "1. e4 e5 2. Nf3 Nc6") + dedupGame("1.2", "1-0", "1. e4 e5 2. Nf3 Nc6 3. Bb5 a6"),
+		dedupGame("?", "1/2-1/2", "1. e4 e5 2. Nf3 Nc6") + dedupGame("?", "1-0", "1. e4 e5 2. Nf3 Nc6 3. Bb5 a6"),
+		dedupGame("1.1", "*", "1. e4 e5 2. Nf3 Nc6") + dedupGame("1.2", "*", "1. e4 e5 2. Nf3 Nc6 3. Bb5 a6"),
+	} {
+		if groups := dedupGroups(t, content); len(groups) != 0 {
+			t.Errorf("Expected no duplicates, got %v in:\n%s", groups, content)
+		}
+	}
+
+	// An unknown round or result is compatible with any
+	groups := dedupGroups(t, dedupGame("?", "*", "1. e4 e5 2. Nf3 Nc6")+dedupGame("1.2", "1-0", "1. e4 e5 2. Nf3 Nc6 3. Bb5 a6"))
+	if len(groups) != 1 || groups[0].Kind != DuplicateTruncated || groups[0].Keep != 1 {
+		t.Errorf("Expected the truncated copy to be grouped, got %v", groups)
+	}
+
+	// A short game joins the group of the longest game it is a prefix of, and does not chain
+	// another longer game to it
+	short, ruyLopez, italian := dedupGame("?", "*", "1. e4 e5 2. Nf3 Nc6"),
+		dedupGame("?", "*", "1. e4 e5 2. Nf3 Nc6 3. Bb5 a6"), dedupGame("?", "*", "1. e4 e5 2. Nf3 Nc6 3. Bc4 Bc5")
+	groups = dedupGroups(t, short+ruyLopez+italian)
+	if len(groups) != 1 || len(groups[0].Games) != 2 || groups[0].Games[1] != 1 {
+		t.Errorf("Expected only the short game and the first longer game grouped, got %v", groups)
+	}
+	// Found after both longer games, it could be a copy of either and joins none
+	if groups := dedupGroups(t, ruyLopez+italian+short); len(groups) != 0 {
+		t.Errorf("Expected no duplicates, got %v", groups)
+	}
+}
+
+func TestPlayerKey(t *testing.T) {
+	tests := map[string]string{
+		"Carlsen, Magnus": "carlsen m",
+		"Carlsen,M.":      "carlsen m",
+		"Magnus Carlsen":  "carlsen m",
+		"Stockfish":       "stockfish",
+		"?":               "",
+	}
+	for name, expected := range tests {
+		if got := playerKey(name); got != expected {
+			t.Errorf("playerKey(%q): expected %q, got %q", name, expected, got)
+		}
+	}
+}
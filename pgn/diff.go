@@ -0,0 +1,193 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change
+const diffContext = 3
+
+// diffLine is a line of a line-based diff: op is ' ' (unchanged), '-' (removed) or '+' (added)
+type diffLine struct {
+	op   byte
+	text string
+}
+
+// diffLines computes a minimal line diff between a and b using the longest common subsequence.
+// It is meant for small inputs such as the lines of a single game.
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{'-', a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{'+', b[j]})
+	}
+
+	return lines
+}
+
+// unifiedDiff writes a line diff in unified format as it is fed, so that large files can be
+// diffed piece by piece while hunks and their context still span piece boundaries.
+type unifiedDiff struct {
+	w        io.Writer
+	eol      string     // line terminator of the diffed file
+	oldLine  int        // line number of the next old line
+	newLine  int        // line number of the next new line
+	before   []diffLine // unchanged lines preceding the next hunk
+	hunk     []diffLine // current hunk, nil if none is open
+	hunkOld  int        // first old line of the current hunk
+	hunkNew  int        // first new line of the current hunk
+	trailing int        // unchanged lines at the end of the current hunk
+}
+
+// newUnifiedDiff creates a unified diff writer for a file using the given line terminator
+func newUnifiedDiff(w io.Writer, eol string) *unifiedDiff {
+	return &unifiedDiff{w: w, eol: eol, oldLine: 1, newLine: 1}
+}
+
+// writeHeader writes the file header, naming the file a/<file> and b/<file> as git does so
+// that the diff applies with "git apply" or "patch -p1"
+func (d *unifiedDiff) writeHeader(filename string) error {
+	name := strings.TrimPrefix(filepath.ToSlash(filepath.Clean(filename)), "/")
+	_, err := fmt.Fprintf(d.w, "--- a/%s%s+++ b/%s%s", name, d.eol, name, d.eol)
+	return err
+}
+
+// add feeds the next lines of the diff
+func (d *unifiedDiff) add(lines []diffLine) error {
+	for _, line := range lines {
+		if line.op == ' ' {
+			if d.hunk != nil && d.trailing < 2*diffContext {
+				d.hunk = append(d.hunk, line)
+				d.trailing++
+			} else {
+				if d.hunk != nil {
+					if err := d.writeHunk(); err != nil {
+						return err
+					}
+				}
+				d.before = append(d.before, line)
+				if len(d.before) > diffContext {
+					d.before = d.before[1:]
+				}
+			}
+			d.oldLine++
+			d.newLine++
+			continue
+		}
+
+		if d.hunk == nil {
+			d.hunk = append([]diffLine{}, d.before...)
+			d.hunkOld = d.oldLine - len(d.before)
+			d.hunkNew = d.newLine - len(d.before)
+			d.before = d.before[:0]
+		}
+		d.hunk = append(d.hunk, line)
+		d.trailing = 0
+		if line.op == '-' {
+			d.oldLine++
+		} else {
+			d.newLine++
+		}
+	}
+	return nil
+}
+
+// addUnchanged feeds lines that are identical in both versions
+func (d *unifiedDiff) addUnchanged(lines []string) error {
+	for _, text := range lines {
+		if err := d.add([]diffLine{{' ', text}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close writes the hunk still open, if any
+func (d *unifiedDiff) close() error {
+	if d.hunk == nil {
+		return nil
+	}
+	return d.writeHunk()
+}
+
+// writeHunk writes the current hunk, keeping diffContext lines of trailing context.
+// Extra trailing lines become the leading context of the next hunk.
+func (d *unifiedDiff) writeHunk() error {
+	extra := max(0, d.trailing-diffContext)
+	hunk := d.hunk[:len(d.hunk)-extra]
+	d.before = append(d.before[:0], d.hunk[len(d.hunk)-extra:]...)
+	if len(d.before) > diffContext {
+		d.before = d.before[len(d.before)-diffContext:]
+	}
+	d.hunk = nil
+	d.trailing = 0
+
+	oldCount, newCount := 0, 0
+	for _, line := range hunk {
+		if line.op != '+' {
+			oldCount++
+		}
+		if line.op != '-' {
+			newCount++
+		}
+	}
+	// An empty range refers to the line before it
+	oldStart, newStart := d.hunkOld, d.hunkNew
+	if oldCount == 0 {
+		oldStart--
+	}
+	if newCount == 0 {
+		newStart--
+	}
+
+	if _, err := fmt.Fprintf(d.w, "@@ -%d,%d +%d,%d @@%s", oldStart, oldCount, newStart, newCount, d.eol); err != nil {
+		return err
+	}
+	for _, line := range hunk {
+		if _, err := fmt.Fprintf(d.w, "%c%s%s", line.op, line.text, d.eol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
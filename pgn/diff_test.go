@@ -0,0 +1,45 @@
+package pgn
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiffAcrossPieces(t *testing.T) {
+	var out bytes.Buffer
+	diff := newUnifiedDiff(&out, "\n")
+
+	// Changes in two pieces separated by fewer than 2*diffContext lines share one hunk
+	diff.addUnchanged([]string{"a", "b", "c", "d"})
+	diff.add(diffLines([]string{"e", "f"}, []string{"E", "f"}))
+	diff.addUnchanged([]string{"g", "h"})
+	diff.add(diffLines([]string{"i"}, []string{"i", "j"}))
+	diff.addUnchanged([]string{"k", "l", "m", "n", "o", "p", "q", "r"})
+	diff.add(diffLines([]string{"s"}, []string{}))
+	diff.close()
+
+	expected := strings.Join([]string{
+		"@@ -2,11 +2,12 @@",
+		" b", " c", " d", "-e", "+E", " f", " g", " h", " i", "+j", " k", " l", " m",
+		"@@ -15,4 +16,3 @@",
+		" p", " q", " r", "-s",
+		"",
+	}, "\n")
+	if out.String() != expected {
+		t.Errorf("Unexpected diff:\n%s\nExpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestUnifiedDiffLineEndings(t *testing.T) {
+	var out bytes.Buffer
+	diff := newUnifiedDiff(&out, "\r\n")
+	diff.writeHeader("games/game.pgn")
+	diff.add(diffLines([]string{"a"}, []string{"b"}))
+	diff.close()
+
+	expected := "--- a/games/game.pgn\r\n+++ b/games/game.pgn\r\n@@ -1,1 +1,1 @@\r\n-a\r\n+b\r\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
@@ -0,0 +1,22 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+// Package pgn reads, validates, corrects and writes chess games in PGN (Portable Game
+// Notation). It is the library behind the pgn_check command.
+//
+// Games are read from any io.Reader with NewGameScanner or ReadGames; a Game holds the lines
+// of one game with its parsed tags, and MoveTree replays its moves. A PGNValidator checks PGN
+// files and reports ValidationErrors, and writes games back with corrections applied
+// (CorrectGames) or in PGN export format (FormatGames):
+//
+//	validator := pgn.NewPGNValidator()
+//	for _, err := range validator.ValidateFile("games.pgn") {
+//		fmt.Println(err)
+//	}
+//	err := validator.FormatGames(ctx, input, output)
+//
+// Functions reading streams stop with the context's error when it is canceled.
+package pgn
@@ -0,0 +1,302 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+//go:embed eco.txt
+var ecoData string
+
+var (
+	// ecoLinePattern matches a line of the ECO table: code, opening, optional variation and moves
+	// Groups: (1) ECO code, (2) opening name, (3) variation name, (4) moves
+	ecoLinePattern = regexp.MustCompile(`^([A-E]\d\d)\s+"([^"]*)"(?:\s+"([^"]*)")?\s+(.*)$`)
+
+	// ecoCodePattern matches a valid ECO code, A00 to E99
+	ecoCodePattern = regexp.MustCompile(`^[A-E]\d\d$`)
+)
+
+// Opening is an entry of the ECO opening table
+type Opening struct {
+	ECO       string // ECO code, e.g. "B90"
+	Name      string // opening name, e.g. "Sicilian"
+	Variation string // variation name, empty if the entry names only the opening
+}
+
+// String returns the code and names of the opening, e.g. "B90 Sicilian, Najdorf"
+func (o *Opening) String() string {
+	if o.Variation == "" {
+		return fmt.Sprintf("%s %s", o.ECO, o.Name)
+	}
+	return fmt.Sprintf("%s %s, %s", o.ECO, o.Name, o.Variation)
+}
+
+// ecoTable indexes the openings of the ECO table by position
+type ecoTable struct {
+	openings map[string]*Opening        // position key -> opening ending in that position
+	lines    map[string]map[string]bool // position key -> ECO codes of the lines passing through it
+	maxPly   int                        // length of the longest line
+}
+
+var (
+	ecoOnce     sync.Once
+	ecoOpenings *ecoTable
+)
+
+// openingTable returns the embedded ECO table, parsed on first use
+func openingTable() *ecoTable {
+	ecoOnce.Do(func() {
+		table, err := parseECOTable(ecoData)
+		if err != nil {
+			panic(fmt.Sprintf("invalid embedded ECO table: %v", err))
+		}
+		ecoOpenings = table
+	})
+	return ecoOpenings
+}
+
+// positionKey identifies a position for classification: piece placement, side to move and
+// castling rights. The en passant square is left out so that transpositions match.
+func positionKey(p *Position) string {
+	return strings.Join(strings.Fields(p.FEN())[:3], " ")
+}
+
+// parseECOTable parses an ECO table: one opening per line, blank lines and lines starting
+// with "#" are ignored. When several lines reach the same position the first one wins.
+func parseECOTable(data string) (*ecoTable, error) {
+	table := &ecoTable{
+		openings: make(map[string]*Opening),
+		lines:    make(map[string]map[string]bool),
+	}
+
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		matches := ecoLinePattern.FindStringSubmatch(line)
+		if matches == nil {
+			return nil, fmt.Errorf("line %d: expected CODE \"Opening\" [\"Variation\"] moves", i+1)
+		}
+		opening := &Opening{ECO: matches[1], Name: matches[2], Variation: matches[3]}
+
+		pos := NewStartPosition()
+		plies := 0
+		for _, tok := range tokenizeMovetext([]string{matches[4]}, i+1) {
+			if tok.Kind != TokenMove {
+				continue
+			}
+			m, err := pos.ParseSAN(tok.Text)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %v", i+1, tok.Text, err)
+			}
+			pos = pos.Play(m)
+			plies++
+
+			key := positionKey(pos)
+			if table.lines[key] == nil {
+				table.lines[key] = make(map[string]bool)
+			}
+			table.lines[key][opening.ECO] = true
+		}
+		if plies == 0 {
+			return nil, fmt.Errorf("line %d: no moves", i+1)
+		}
+
+		if key := positionKey(pos); table.openings[key] == nil {
+			table.openings[key] = opening
+		}
+		if plies > table.maxPly {
+			table.maxPly = plies
+		}
+	}
+
+	return table, nil
+}
+
+// classifyOpening replays the mainline of a game and returns the opening of the last table
+// position it reaches, or nil if none. It also returns the ECO codes consistent with the
+// game: those of the table positions reached and those of the table lines continuing from
+// the last position shared with the table, which finer classifications may use.
+// Games starting from a FEN position are not classified.
+func classifyOpening(g *Game) (*Opening, map[string]bool) {
+	if g.HasTag("FEN") {
+		return nil, nil
+	}
+	table := openingTable()
+
+	var opening *Opening
+	var continuations map[string]bool
+	codes := make(map[string]bool)
+	pos := NewStartPosition()
+	depth, plies := 0, 0
+
+	for _, tok := range tokenizeMovetext(g.Movetext(), g.MovetextLine()) {
+		switch tok.Kind {
+		case TokenVariationStart:
+			depth++
+		case TokenVariationEnd:
+			if depth > 0 {
+				depth--
+			}
+		case TokenMove:
+			if depth > 0 {
+				continue
+			}
+			m, err := pos.ParseSAN(tok.Text)
+			if err != nil {
+				return opening, mergeCodes(codes, continuations)
+			}
+			pos = pos.Play(m)
+			plies++
+
+			key := positionKey(pos)
+			if found := table.openings[key]; found != nil {
+				opening = found
+				codes[found.ECO] = true
+			}
+			if lines := table.lines[key]; lines != nil {
+				continuations = lines
+			}
+			if plies >= table.maxPly {
+				return opening, mergeCodes(codes, continuations)
+			}
+		}
+	}
+
+	return opening, mergeCodes(codes, continuations)
+}
+
+// mergeCodes adds the codes of extra to codes and returns it
+func mergeCodes(codes, extra map[string]bool) map[string]bool {
+	for code := range extra {
+		codes[code] = true
+	}
+	return codes
+}
+
+// normalizeECO returns an ECO tag value trimmed and in upper case
+func normalizeECO(value string) string {
+	return strings.ToUpper(strings.TrimSpace(value))
+}
+
+// validateECO checks the ECO tag of a game: its format, and with SetOpeningTags that it
+// agrees with the opening played
+func (v *PGNValidator) validateECO(g *Game) {
+	var tag *Tag
+	for i := range g.Tags {
+		if strings.EqualFold(g.Tags[i].Name, "ECO") {
+			tag = &g.Tags[i]
+			break
+		}
+	}
+	if tag == nil {
+		return
+	}
+	eco := normalizeECO(tag.Value)
+	if eco == "" || eco == "?" {
+		return
+	}
+
+	if !ecoCodePattern.MatchString(eco) {
+		v.errors = append(v.errors, ValidationError{
+			Line:    tag.Line,
+			Message: fmt.Sprintf("Invalid ECO code '%s' (expected A00-E99)", tag.Value),
+			Rule:    RuleECO,
+		})
+		return
+	}
+
+	// Databases classify games their own way, so the opening is only checked on request
+	if !v.openingTags {
+		return
+	}
+	opening, codes := classifyOpening(g)
+	if opening == nil || codes[eco] {
+		return
+	}
+	v.errors = append(v.errors, ValidationError{
+		Line:     tag.Line,
+		Message:  fmt.Sprintf("Warning: ECO '%s' does not match the opening played (%s)", tag.Value, opening),
+		Rule:     RuleECO,
+		Severity: SeverityWarning,
+	})
+}
+
+// SetOpeningTags sets whether validations check ECO tags against the opening played, and
+// corrections add missing ECO, Opening and Variation tags and fix ECO tags that do not match
+func (v *PGNValidator) SetOpeningTags(enabled bool) {
+	v.openingTags = enabled
+}
+
+// correctOpeningTags returns the tag section of a game with ECO, Opening and Variation
+// tags added or fixed from the classification of its moves
+func correctOpeningTags(g *Game, lines []string) []string {
+	if len(g.Tags) == 0 {
+		return lines
+	}
+	opening, codes := classifyOpening(g)
+	if opening == nil {
+		return lines
+	}
+
+	eco := normalizeECO(g.Tag("ECO"))
+	replace := eco != "" && eco != "?" && !(ecoCodePattern.MatchString(eco) && codes[eco])
+	if eco != "" && eco != "?" && !replace {
+		return lines
+	}
+
+	values := map[string]string{"ECO": opening.ECO, "Opening": opening.Name, "Variation": opening.Variation}
+	present := make(map[string]bool)
+	corrected := make([]string, 0, len(lines)+3)
+	end := 0 // position after the last tag
+	for _, line := range lines {
+		matches := tagPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches != nil {
+			// Tag names are matched as validateECO does, ignoring case
+			if name := openingTagName(matches[1]); name != "" {
+				present[name] = true
+				// An unknown ECO is always replaced, names only with a wrong ECO
+				if replace || name == "ECO" {
+					if values[name] == "" {
+						// The new opening has no variation name
+						continue
+					}
+					line = fmt.Sprintf("[%s \"%s\"]", name, values[name])
+				}
+			}
+			end = len(corrected) + 1
+		}
+		corrected = append(corrected, line)
+	}
+
+	// Add the missing tags after the last one
+	var added []string
+	for _, name := range []string{"ECO", "Opening", "Variation"} {
+		if !present[name] && values[name] != "" {
+			added = append(added, fmt.Sprintf("[%s \"%s\"]", name, values[name]))
+		}
+	}
+	return append(corrected[:end], append(added, corrected[end:]...)...)
+}
+
+// openingTagName returns the name of the ECO, Opening or Variation tag as spelled in PGN,
+// whatever the case of name, or "" for another tag
+func openingTagName(name string) string {
+	for _, known := range []string{"ECO", "Opening", "Variation"} {
+		if strings.EqualFold(name, known) {
+			return known
+		}
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+package pgn
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseECOTable(t *testing.T) {
+	table, err := parseECOTable(ecoData)
+	if err != nil {
+		t.Fatalf("Embedded ECO table is invalid: %v", err)
+	}
+	if len(table.openings) < 1000 {
+		t.Errorf("Expected at least 1000 openings, got %d", len(table.openings))
+	}
+
+	for _, data := range []string{
+		`A00 "Polish" 1. b5`,
+		`A00 Polish 1. b4`,
+		`F00 "Unknown" 1. e4`,
+	} {
+		if _, err := parseECOTable(data); err == nil {
+			t.Errorf("Expected an error for %q", data)
+		}
+	}
+}
+
+func TestClassifyOpening(t *testing.T) {
+	tests := []struct {
+		movetext string
+		expected string
+	}{
+		{"1. e4 c5 2. Nf3 d6 3. d4 cxd4 4. Nxd4 Nf6 5. Nc3 a6 6. h3 e5 1-0", "B90 Sicilian, Najdorf"},
+		// Transposition: the Queen's Gambit Declined reached from the English opening
+		{"1. c4 e6 2. Nc3 d5 3. d4 Nf6 *", "D35 QGD, 3...Nf6"},
+		// Moves in variations are not played
+		{"1. e4 (1. d4 d5) 1... e5 2. Nf3 *", "C40 King's knight opening"},
+		{"1. a4 a5 2. b4 *", "A00 Ware (Meadow Hay) opening"},
+	}
+
+	for _, tt := range tests {
+		game := &Game{Lines: []string{tt.movetext}}
+		opening, _ := classifyOpening(game)
+		if opening == nil {
+			t.Errorf("%s: expected %s, got no opening", tt.movetext, tt.expected)
+			continue
+		}
+		if !strings.HasPrefix(opening.String(), tt.expected) {
+			t.Errorf("%s: expected %s, got %s", tt.movetext, tt.expected, opening)
+		}
+	}
+
+	game := &Game{Lines: []string{`[FEN "8/8/8/8/8/8/4K3/4k3 w - - 0 1"]`, "", "1. Kd3 *"}, MovetextStart: 1}
+	game.parseTags()
+	if opening, _ := classifyOpening(game); opening != nil {
+		t.Errorf("Expected no opening for a game starting from a FEN position, got %s", opening)
+	}
+}
+
+func TestValidateECO(t *testing.T) {
+	content := `[Event "Open"]
+[ECO "B90"]
+[Result "*"]
+
+1. e4 c5 2. Nf3 d6 3. d4 cxd4 4. Nxd4 Nf6 5. Nc3 a6 *
+
+[Event "Open"]
+[ECO "B99"]
+[Result "*"]
+
+1. e4 c5 2. Nf3 d6 3. d4 cxd4 4. Nxd4 Nf6 5. Nc3 a6 *
+
+[Event "Open"]
+[eco "C20"]
+[Result "*"]
+
+1. d4 Nf6 2. c4 e6 3. Nc3 Bb4 *
+
+[Event "Open"]
+[ECO "Z12"]
+[Result "*"]
+
+1. d4 d5 *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	// The opening played is only checked with SetOpeningTags
+	validator := NewPGNValidator()
+	if errors := validator.ValidateFile(tmpFile); len(errors) != 1 || errors[0].Line != 20 {
+		t.Errorf("Expected only the invalid code to be reported, got %v", errors)
+	}
+
+	validator.SetOpeningTags(true)
+	errors := validator.ValidateFile(tmpFile)
+
+	expected := []string{
+		"Line 14: Warning: ECO 'C20' does not match the opening played (E20 Nimzo-Indian defence)",
+		"Line 20: Invalid ECO code 'Z12' (expected A00-E99)",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, err := range errors {
+		if err.String() != expected[i] || err.Rule != RuleECO {
+			t.Errorf("Message %d:\n  expected: %s\n  got:      %s (%s)", i, expected[i], err.String(), err.Rule)
+		}
+	}
+}
+
+func TestWriteCorrectedFileOpeningTags(t *testing.T) {
+	content := `[Event "Open"]
+[Result "*"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 *
+
+[Event "Open"]
+[ECO "A00"]
+[Opening "Polish"]
+[Variation "Tuebingen variation"]
+[Result "*"]
+
+1. d4 d5 2. c4 *
+
+[Event "Open"]
+[ECO "?"]
+[Result "*"]
+
+1. e4 c5 *
+
+[Event "Open"]
+[eco "C20"]
+[opening "King's pawn game"]
+[Result "*"]
+
+1. d4 Nf6 2. c4 e6 3. Nc3 Bb4 *
+`
+	dir := t.TempDir()
+	inputFile := filepath.Join(dir, "input.pgn")
+	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+
+	validator := NewPGNValidator()
+	validator.SetOpeningTags(true)
+	outputFile := filepath.Join(dir, "output.pgn")
+	if err := validator.WriteCorrectedFile(inputFile, outputFile); err != nil {
+		t.Fatalf("WriteCorrectedFile failed: %v", err)
+	}
+	output, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	expected := `[Event "Open"]
+[Result "*"]
+[ECO "C60"]
+[Opening "Ruy Lopez (Spanish opening)"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 *
+
+[Event "Open"]
+[ECO "D06"]
+[Opening "Queen's Gambit"]
+[Result "*"]
+
+1. d4 d5 2. c4 *
+
+[Event "Open"]
+[ECO "B20"]
+[Result "*"]
+[Opening "Sicilian defence"]
+
+1. e4 c5 *
+
+[Event "Open"]
+[ECO "E20"]
+[Opening "Nimzo-Indian defence"]
+[Result "*"]
+
+1. d4 Nf6 2. c4 e6 3. Nc3 Bb4 *
+`
+	if string(output) != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", output, expected)
+	}
+}
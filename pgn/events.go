@@ -0,0 +1,286 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// roundPattern matches a Round value made of dot-separated numbers: "5", "5.12", "1.2.3"
+var roundPattern = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// eventGame holds the tags of a game needed by the cross-game checks of its event
+type eventGame struct {
+	date, eventDate string // full dates in YYYY.MM.DD format, "" if unknown
+	round           string
+	white, black    string // normalized player names
+	teams           string // "WhiteTeam|BlackTeam" for team events, "" otherwise
+	dateLine        int
+	roundLine       int
+	whiteLine       int
+	blackLine       int
+}
+
+// eventValue is one value of a tag shared by the games of an event
+type eventValue struct {
+	value string
+	line  int // first line where the value appears
+}
+
+// eventGames collects the games of one event, in file order
+type eventGames struct {
+	name       string
+	games      []eventGame
+	sites      []eventValue
+	eventDates []eventValue
+}
+
+// validateRound checks that a Round value is "?", "-" or a sequence of dot-separated numbers
+func (v *PGNValidator) validateRound(round string, lineNumber int) {
+	if round == "?" || round == "-" || round == "" || roundPattern.MatchString(round) {
+		return
+	}
+	v.errors = append(v.errors, ValidationError{
+		Line:     lineNumber,
+		Message:  fmt.Sprintf("Warning: Implausible Round value: '%s'", round),
+		Rule:     RuleRound,
+		Severity: SeverityWarning,
+	})
+}
+
+// fullDate returns a date tag value in YYYY.MM.DD format, correcting it if possible.
+// It returns "" for unknown or partially known dates.
+func (v *PGNValidator) fullDate(value string) string {
+	if correctDatePattern.MatchString(value) {
+		return value
+	}
+	if wildcardDatePattern.MatchString(value) || strings.Contains(value, "?") {
+		return ""
+	}
+	if corrected, err := v.tryFixDate(value); err == nil {
+		return corrected
+	}
+	return ""
+}
+
+// addEventValue records a value of a tag shared by the games of an event
+func addEventValue(values []eventValue, value string, line int) []eventValue {
+	if value == "" || value == "?" {
+		return values
+	}
+	for _, existing := range values {
+		if existing.value == value {
+			return values
+		}
+	}
+	return append(values, eventValue{value: value, line: line})
+}
+
+// recordEventGame remembers the tags of a game for the checks across the games of its event
+func (v *PGNValidator) recordEventGame(g *Game) {
+	name := collapseSpaces(g.Tag("Event"))
+	if name == "" || name == "?" {
+		return
+	}
+
+	event := v.events[name]
+	if event == nil {
+		event = &eventGames{name: name}
+		v.events[name] = event
+	}
+
+	game := eventGame{round: strings.TrimSpace(g.Tag("Round"))}
+	for _, tag := range g.Tags {
+		switch strings.ToLower(tag.Name) {
+		case "site":
+			event.sites = addEventValue(event.sites, collapseSpaces(tag.Value), tag.Line)
+		case "date":
+			game.date, game.dateLine = v.fullDate(tag.Value), tag.Line
+		case "eventdate":
+			game.eventDate = v.fullDate(tag.Value)
+			event.eventDates = addEventValue(event.eventDates, game.eventDate, tag.Line)
+		case "round":
+			game.roundLine = tag.Line
+		case "white":
+			game.white, game.whiteLine = v.normalizePlayerName(tag.Value), tag.Line
+		case "black":
+			game.black, game.blackLine = v.normalizePlayerName(tag.Value), tag.Line
+		}
+	}
+	if g.HasTag("WhiteTeam") || g.HasTag("BlackTeam") {
+		game.teams = collapseSpaces(g.Tag("WhiteTeam")) + "|" + collapseSpaces(g.Tag("BlackTeam"))
+	}
+
+	event.games = append(event.games, game)
+}
+
+// checkEvents runs the checks across the games of each event: Site and EventDate
+// consistency, games dated before their event, duplicated boards and players paired twice
+// in the same round
+func (v *PGNValidator) checkEvents() {
+	names := make([]string, 0, len(v.events))
+	for name := range v.events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		event := v.events[name]
+		v.checkEventSites(event)
+		v.checkEventDates(event)
+		v.checkEventBoards(event)
+		v.checkEventPairings(event)
+	}
+}
+
+// checkEventSites reports Site values of an event that look like spellings of the same place,
+// such as "Douglas ENG" and "Douglas, ENG". Events played in several places are not reported.
+func (v *PGNValidator) checkEventSites(event *eventGames) {
+	for i, a := range event.sites {
+		for _, b := range event.sites[i+1:] {
+			keyA, keyB := alphanumeric(a.value), alphanumeric(b.value)
+			if strings.HasPrefix(keyA, keyB) || strings.HasPrefix(keyB, keyA) {
+				v.errors = append(v.errors, ValidationError{
+					Line: b.line,
+					Message: fmt.Sprintf("Warning: Conflicting Site spellings in event '%s': '%s' (line %d), '%s' (line %d)",
+						event.name, a.value, a.line, b.value, b.line),
+					Rule:     RuleEventSite,
+					Severity: SeverityWarning,
+				})
+			}
+		}
+	}
+}
+
+// checkEventDates reports conflicting EventDate values and games dated before their event
+func (v *PGNValidator) checkEventDates(event *eventGames) {
+	if len(event.eventDates) > 1 {
+		first, second := event.eventDates[0], event.eventDates[1]
+		v.errors = append(v.errors, ValidationError{
+			Line: second.line,
+			Message: fmt.Sprintf("Warning: Conflicting EventDate values in event '%s': '%s' (line %d), '%s' (line %d)",
+				event.name, first.value, first.line, second.value, second.line),
+			Rule:     RuleEventDate,
+			Severity: SeverityWarning,
+		})
+	}
+
+	for _, game := range event.games {
+		eventDate := game.eventDate
+		if eventDate == "" && len(event.eventDates) > 0 {
+			eventDate = event.eventDates[0].value
+		}
+		if game.date != "" && eventDate != "" && game.date < eventDate {
+			v.errors = append(v.errors, ValidationError{
+				Line:     game.dateLine,
+				Message:  fmt.Sprintf("Warning: Date '%s' is earlier than EventDate '%s' of event '%s'", game.date, eventDate, event.name),
+				Rule:     RuleEventDate,
+				Severity: SeverityWarning,
+			})
+		}
+	}
+}
+
+// checkEventBoards reports Round values of the form "round.board" used by more than one
+// game. Team matches and knockout events reuse board numbers in every match, so a round is
+// only checked when its boards are otherwise unique.
+func (v *PGNValidator) checkEventBoards(event *eventGames) {
+	boards := make(map[string][]eventGame) // round, board and teams -> games
+	unique := make(map[string]int)         // round -> boards used by a single game
+	duplicated := make(map[string]int)     // round -> boards used by several games
+
+	for _, game := range event.games {
+		if !strings.Contains(game.round, ".") || !roundPattern.MatchString(game.round) {
+			continue
+		}
+		key := game.round + "|" + game.teams
+		boards[key] = append(boards[key], game)
+	}
+	for key, games := range boards {
+		round, _, _ := strings.Cut(key, ".")
+		if len(games) > 1 {
+			duplicated[round]++
+		} else {
+			unique[round]++
+		}
+	}
+
+	keys := make([]string, 0, len(boards))
+	for key, games := range boards {
+		round, _, _ := strings.Cut(key, ".")
+		if len(games) > 1 && duplicated[round] < unique[round] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		games := boards[key]
+		lines := make([]string, len(games))
+		for i, game := range games {
+			lines[i] = fmt.Sprint(game.roundLine)
+		}
+		v.errors = append(v.errors, ValidationError{
+			Line: games[1].roundLine,
+			Message: fmt.Sprintf("Warning: Board '%s' of event '%s' is used by more than one game (lines %s)",
+				games[0].round, event.name, strings.Join(lines, ", ")),
+			Rule:     RuleBoard,
+			Severity: SeverityWarning,
+		})
+	}
+}
+
+// checkEventPairings reports players paired against different opponents in the same round.
+// Several games against the same opponent are the games of a match and are not reported.
+func (v *PGNValidator) checkEventPairings(event *eventGames) {
+	type appearance struct {
+		opponent string
+		line     int
+	}
+	seen := make(map[string]appearance) // round and player -> first appearance
+	reported := make(map[string]bool)
+
+	for _, game := range event.games {
+		if !roundPattern.MatchString(game.round) {
+			continue
+		}
+		round, _, _ := strings.Cut(game.round, ".")
+
+		players := []struct {
+			name, opponent string
+			line           int
+		}{
+			{game.white, game.black, game.whiteLine},
+			{game.black, game.white, game.blackLine},
+		}
+		for _, player := range players {
+			if player.name == "" || player.name == "?" {
+				continue
+			}
+			key := round + "|" + player.name
+			first, ok := seen[key]
+			if !ok {
+				seen[key] = appearance{opponent: player.opponent, line: player.line}
+				continue
+			}
+			if first.opponent == player.opponent || reported[key] {
+				continue
+			}
+			reported[key] = true
+			v.errors = append(v.errors, ValidationError{
+				Line: player.line,
+				Message: fmt.Sprintf("Warning: Player '%s' appears twice in round %s of event '%s' (lines %d, %d)",
+					player.name, round, event.name, first.line, player.line),
+				Rule:     RulePairing,
+				Severity: SeverityWarning,
+			})
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package pgn
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEventChecks(t *testing.T) {
+	content := `[Event "Open"]
+[Site "Douglas ENG"]
+[Date "2023.09.30"]
+[Round "1.1"]
+[White "Adams, Michael"]
+[Black "Short, Nigel"]
+[Result "*"]
+[EventDate "2023.10.01"]
+
+1. e4 *
+
+[Event "Open"]
+[Site "Douglas  eng"]
+[Date "2023.10.01"]
+[Round "1.1"]
+[White "Howell, David"]
+[Black "Jones, Gawain"]
+[Result "*"]
+
+1. d4 *
+
+[Event "Open"]
+[Site "Douglas ENG"]
+[Date "2023.10.01"]
+[Round "1.2"]
+[White "Adams, Michael"]
+[Black "Pert, Nicholas"]
+[Result "*"]
+
+1. c4 *
+
+[Event "Open"]
+[Site "Douglas ENG"]
+[Date "2023.10.01"]
+[Round "1.3"]
+[White "Pert, Richard"]
+[Black "Gormally, Daniel"]
+[Result "*"]
+
+1. Nf3 *
+
+[Event "Open"]
+[Site "Douglas ENG"]
+[Date "2023.10.01"]
+[Round "first"]
+[White "Pert, Richard"]
+[Black "Gormally, Daniel"]
+[Result "*"]
+
+1. g3 *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	validator := NewPGNValidator()
+	errors := validator.ValidateFile(tmpFile)
+
+	expected := []string{
+		"Line 3: Warning: Date '2023.09.30' is earlier than EventDate '2023.10.01' of event 'Open'",
+		"Line 13: Warning: Conflicting Site spellings in event 'Open': 'Douglas ENG' (line 2), 'Douglas eng' (line 13)",
+		"Line 15: Warning: Board '1.1' of event 'Open' is used by more than one game (lines 4, 15)",
+		"Line 26: Warning: Player 'Adams, Michael' appears twice in round 1 of event 'Open' (lines 5, 26)",
+		"Line 45: Warning: Implausible Round value: 'first'",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, err := range errors {
+		if err.String() != expected[i] {
+			t.Errorf("Message %d:\n  expected: %s\n  got:      %s", i, expected[i], err.String())
+		}
+	}
+}
+
+func TestEventChecksMatchesAndTeams(t *testing.T) {
+	// Knockout games reuse "round.game" numbers and pair the same players several times;
+	// team matches reuse board numbers in every match
+	content := `[Event "Cup"]
+[Round "1.1"]
+[White "Carlsen, Magnus"]
+[Black "Caruana, Fabiano"]
+
+1. e4 *
+
+[Event "Cup"]
+[Round "1.2"]
+[White "Caruana, Fabiano"]
+[Black "Carlsen, Magnus"]
+
+1. d4 *
+
+[Event "Cup"]
+[Round "1.1"]
+[White "Giri, Anish"]
+[Black "So, Wesley"]
+
+1. c4 *
+
+[Event "Cup"]
+[Round "1.2"]
+[White "So, Wesley"]
+[Black "Giri, Anish"]
+
+1. Nf3 *
+
+[Event "League"]
+[Round "2.1"]
+[White "Adams, Michael"]
+[Black "Short, Nigel"]
+[WhiteTeam "Guildford"]
+[BlackTeam "Wood Green"]
+
+1. e4 *
+
+[Event "League"]
+[Round "2.1"]
+[White "Howell, David"]
+[Black "Jones, Gawain"]
+[WhiteTeam "Manx"]
+[BlackTeam "Cheddleton"]
+
+1. e4 *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	validator := NewPGNValidator()
+	if errors := validator.ValidateFile(tmpFile); len(errors) != 0 {
+		t.Errorf("Expected no messages, got %v", errors)
+	}
+}
@@ -0,0 +1,348 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// queryOperators are the comparison operators of a query, longest first
+var queryOperators = []string{"==", "!=", "!~", "<=", ">=", "~", "<", ">"}
+
+// Query selects games by their tags, e.g. White~"Carlsen" && Date>=2023.01.01 && Result=="1-0"
+type Query struct {
+	root      queryNode
+	validator *PGNValidator
+}
+
+// queryNode is a node of a parsed query expression
+type queryNode interface {
+	match(q *Query, g *Game) bool
+}
+
+type queryAnd struct{ left, right queryNode }
+type queryOr struct{ left, right queryNode }
+type queryNot struct{ operand queryNode }
+
+// queryComparison compares a tag, or the Player pseudo-tag (White or Black), with a value
+type queryComparison struct {
+	field string
+	op    string
+	value string
+}
+
+func (n queryAnd) match(q *Query, g *Game) bool { return n.left.match(q, g) && n.right.match(q, g) }
+func (n queryOr) match(q *Query, g *Game) bool  { return n.left.match(q, g) || n.right.match(q, g) }
+func (n queryNot) match(q *Query, g *Game) bool { return !n.operand.match(q, g) }
+
+func (n queryComparison) match(q *Query, g *Game) bool {
+	fields := []string{n.field}
+	if strings.EqualFold(n.field, "Player") {
+		fields = []string{"White", "Black"}
+	}
+
+	// Negated operators hold when the positive comparison fails for every field
+	op, negate := n.op, false
+	switch op {
+	case "!=":
+		op, negate = "==", true
+	case "!~":
+		op, negate = "~", true
+	}
+	for _, field := range fields {
+		if compareQueryValue(q.tagValue(g, field), op, n.value) {
+			return !negate
+		}
+	}
+	return negate
+}
+
+// tagValue returns the value of a tag as compared by queries: trimmed, with dates, results
+// and player names normalized
+func (q *Query) tagValue(g *Game, name string) string {
+	value := strings.TrimSpace(g.Tag(name))
+	switch strings.ToLower(name) {
+	case "date", "eventdate":
+		if date := q.validator.fullDate(value); date != "" {
+			return date
+		}
+	case "result":
+		if result := normalizeResult(value); result != "" {
+			return result
+		}
+	case "white", "black":
+		return q.validator.normalizePlayerName(value)
+	}
+	return value
+}
+
+// compareQueryValue applies a positive comparison operator. "~" is a case-insensitive
+// substring match. Ordering operators compare numbers numerically and other values in ASCII
+// order; they never match missing or unknown values.
+func compareQueryValue(value, op, operand string) bool {
+	switch op {
+	case "==":
+		return value == operand
+	case "~":
+		return strings.Contains(strings.ToLower(value), strings.ToLower(operand))
+	}
+
+	if value == "" || strings.Contains(value, "?") {
+		return false
+	}
+	cmp := strings.Compare(value, operand)
+	a, errA := strconv.ParseFloat(value, 64)
+	b, errB := strconv.ParseFloat(operand, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case a < b:
+			cmp = -1
+		case a > b:
+			cmp = 1
+		default:
+			cmp = 0
+		}
+	}
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+// queryParser is a recursive descent parser for query expressions:
+//
+//	expr       = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" expr ")" | comparison
+//	comparison = tag operator value
+type queryParser struct {
+	input string
+	pos   int
+}
+
+// ParseQuery parses a query expression. The validator normalizes player names and dates
+// before they are compared.
+func ParseQuery(expr string, validator *PGNValidator) (*Query, error) {
+	p := &queryParser{input: expr}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpaces(); p.pos < len(p.input) {
+		return nil, fmt.Errorf("unexpected '%s' at position %d", p.input[p.pos:], p.pos+1)
+	}
+	return &Query{root: root, validator: validator}, nil
+}
+
+// Match reports whether a game satisfies the query
+func (q *Query) Match(g *Game) bool {
+	return q.root.match(q, g)
+}
+
+func (p *queryParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// accept consumes token if it comes next
+func (p *queryParser) accept(token string) bool {
+	p.skipSpaces()
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = queryOr{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = queryAnd{left, right}
+	}
+	return left, nil
+}
+
+func (p *queryParser) parseUnary() (queryNode, error) {
+	if p.accept("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{operand}, nil
+	}
+	if p.accept("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ')' at position %d", p.pos+1)
+		}
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *queryParser) parseComparison() (queryNode, error) {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) && (isTagNameChar(p.input[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		if p.pos == len(p.input) {
+			return nil, fmt.Errorf("unexpected end of query, expected a tag name")
+		}
+		return nil, fmt.Errorf("expected a tag name at position %d", p.pos+1)
+	}
+	field := p.input[start:p.pos]
+
+	op := ""
+	for _, candidate := range queryOperators {
+		if p.accept(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("expected an operator (%s) after '%s'", strings.Join(queryOperators, " "), field)
+	}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return queryComparison{field: field, op: op, value: value}, nil
+}
+
+// parseValue reads a quoted string, where \" and \\ are escapes, or a bare word such as
+// 2023.01.01 or 1-0
+func (p *queryParser) parseValue() (string, error) {
+	p.skipSpaces()
+	if p.pos < len(p.input) && p.input[p.pos] == '"' {
+		var sb strings.Builder
+		for i := p.pos + 1; i < len(p.input); i++ {
+			switch c := p.input[i]; {
+			case c == '\\' && i+1 < len(p.input):
+				i++
+				sb.WriteByte(p.input[i])
+			case c == '"':
+				p.pos = i + 1
+				return sb.String(), nil
+			default:
+				sb.WriteByte(c)
+			}
+		}
+		return "", fmt.Errorf("unterminated string at position %d", p.pos+1)
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && !unicode.IsSpace(rune(p.input[p.pos])) && !strings.ContainsRune("()&|", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	if p.pos == start {
+		return "", fmt.Errorf("expected a value at position %d", p.pos+1)
+	}
+	return p.input[start:p.pos], nil
+}
+
+// isTagNameChar reports whether c may appear in a tag name
+func isTagNameChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// WriteExtractedFiles writes the games of the input files matching the query to w, copying
+// their lines as writeGames does or, if canonical is set, in PGN export format. It returns the
+// number of games written and read.
+func (v *PGNValidator) WriteExtractedFiles(inputFiles []string, q *Query, w io.Writer, canonical bool) (int, int, error) {
+	return v.writeGames(inputFiles, w, canonical, q.Match)
+}
+
+// writeGames writes the games of the input files accepted by match to w, separated by one
+// blank line, with the line endings of the first input file. It returns the number of games
+// written and read.
+func (v *PGNValidator) writeGames(inputFiles []string, w io.Writer, canonical bool, match func(*Game) bool) (int, int, error) {
+	// Increase writer buffer size to 1MB
+	writer := bufio.NewWriterSize(w, 1024*1024)
+	matched, total := 0, 0
+	eol := ""
+
+	for _, filename := range inputFiles {
+		file, err := os.Open(filename)
+		if err != nil {
+			return matched, total, fmt.Errorf("cannot open input file: %v", err)
+		}
+
+		scanner := NewGameScanner(file)
+		for scanner.Scan() {
+			game := scanner.Game()
+			if !hasContent(game) {
+				continue
+			}
+			total++
+			if !match(game) {
+				continue
+			}
+			matched++
+
+			if eol == "" {
+				eol = scanner.LineEnding()
+			}
+			if canonical {
+				_, err = writer.WriteString(v.formatGame(game))
+			} else {
+				err = writeGameLines(writer, game, eol)
+			}
+			if err != nil {
+				file.Close()
+				return matched, total, fmt.Errorf("error writing: %v", err)
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return matched, total, fmt.Errorf("error reading: %v", err)
+		}
+	}
+
+	return matched, total, writer.Flush()
+}
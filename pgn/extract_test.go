@@ -0,0 +1,119 @@
+package pgn
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestQueryMatch(t *testing.T) {
+	game := &Game{Lines: []string{
+		`[Event "Titled Tuesday"]`,
+		`[Date "2023-05-02"]`,
+		`[White "Carlsen, Magnus"]`,
+		`[Black "Nakamura, Hikaru"]`,
+		`[Result "1-0"]`,
+		`[WhiteElo "2853"]`,
+		`[ECO "B90"]`,
+		"",
+		"1. e4 c5 1-0",
+	}, MovetextStart: 8}
+	game.parseTags()
+
+	tests := []struct {
+		query    string
+		expected bool
+	}{
+		{`White~"Carlsen" && Date>=2023.01.01 && Result=="1-0"`, true},
+		{`White~"carlsen" && Date<2023.01.01`, false},
+		{`Player=="Nakamura, Hikaru"`, true},
+		{`Player!="Nakamura, Hikaru"`, false},
+		{`Player!~"Caruana"`, true},
+		{`ECO>=B20 && ECO<=B99`, true},
+		{`WhiteElo>=2800 && WhiteElo<3000`, true},
+		{`WhiteElo>900`, true},
+		{`BlackElo>0`, false},
+		{`Round==""`, true},
+		{`!(Result=="1-0") || Event~Tuesday`, true},
+		{`Result=="0-1" || Result=="1/2-1/2"`, false},
+		{`Event=="Titled \"Tuesday\""`, false},
+	}
+
+	validator := NewPGNValidator()
+	for _, tt := range tests {
+		query, err := ParseQuery(tt.query, validator)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", tt.query, err)
+			continue
+		}
+		if got := query.Match(game); got != tt.expected {
+			t.Errorf("%s: expected %v, got %v", tt.query, tt.expected, got)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`White`,
+		`White~`,
+		`White~"Carlsen`,
+		`(White~Carlsen`,
+		`White~Carlsen &&`,
+		`White~Carlsen Black~Caruana`,
+	} {
+		if _, err := ParseQuery(expr, NewPGNValidator()); err == nil {
+			t.Errorf("Expected an error for %q", expr)
+		}
+	}
+}
+
+func TestWriteExtractedFiles(t *testing.T) {
+	content := `[Event "Open"]
+[White "Carlsen, Magnus"]
+[Black "Caruana, Fabiano"]
+[Result "1-0"]
+
+1. e4 e5 1-0
+
+[Event "Open"]
+[White "Caruana, Fabiano"]
+[Black "Carlsen, Magnus"]
+[Result "0-1"]
+
+1. d4   d5 0-1
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	validator := NewPGNValidator()
+	query, err := ParseQuery(`Black~Carlsen`, validator)
+	if err != nil {
+		t.Fatalf("ParseQuery failed: %v", err)
+	}
+
+	var out bytes.Buffer
+	matched, total, err := validator.WriteExtractedFiles([]string{tmpFile}, query, &out, false)
+	if err != nil {
+		t.Fatalf("WriteExtractedFiles failed: %v", err)
+	}
+	expected := `[Event "Open"]
+[White "Caruana, Fabiano"]
+[Black "Carlsen, Magnus"]
+[Result "0-1"]
+
+1. d4   d5 0-1
+
+`
+	if matched != 1 || total != 2 || out.String() != expected {
+		t.Errorf("Expected 1 of 2 games, got %d of %d:\n%s", matched, total, out.String())
+	}
+
+	out.Reset()
+	if _, _, err := validator.WriteExtractedFiles([]string{tmpFile}, query, &out, true); err != nil {
+		t.Fatalf("WriteExtractedFiles failed: %v", err)
+	}
+	if !bytes.Contains(out.Bytes(), []byte("\n1. d4 d5 0-1\n")) {
+		t.Errorf("Expected the game in export format, got:\n%s", out.String())
+	}
+}
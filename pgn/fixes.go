@@ -0,0 +1,125 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Edit replaces part of a line. Columns are 0-based byte offsets; EndCol is just past the
+// replaced text.
+type Edit struct {
+	Line        int
+	Col, EndCol int
+	NewText     string
+}
+
+// Fix is a correction of a game offered separately from the others, e.g. as an editor
+// quick fix
+type Fix struct {
+	Title         string   // what the fix does, e.g. "Correct date to 2024.01.05"
+	Line, EndLine int      // lines the fix is about, for matching it to errors
+	Rules         []string // checks whose errors the fix answers
+	Edits         []Edit   // changes to apply together, on distinct lines
+}
+
+// Fixes returns the corrections of a game's malformed dates, unbalanced comments and
+// variations, and moves not written in canonical SAN
+func (v *PGNValidator) Fixes(g *Game) []Fix {
+	fixes := []Fix{}
+
+	for _, tag := range g.Tags {
+		name := strings.ToLower(tag.Name)
+		if name != "date" && name != "eventdate" ||
+			correctDatePattern.MatchString(tag.Value) || wildcardDatePattern.MatchString(tag.Value) {
+			continue
+		}
+		date, err := v.tryFixDate(tag.Value)
+		if err != nil {
+			continue
+		}
+		line := g.Lines[tag.Line-g.StartLine]
+		corrected := strings.Replace(line, "\""+tag.Value+"\"", "\""+date+"\"", 1)
+		if corrected != line {
+			fixes = append(fixes, Fix{
+				Title: fmt.Sprintf("Correct date to %s", date),
+				Line:  tag.Line, EndLine: tag.Line,
+				Rules: []string{RuleDate},
+				Edits: []Edit{lineEdit(tag.Line, line, corrected)},
+			})
+		}
+	}
+
+	movetext := g.Movetext()
+	var edits []Edit
+	for i, line := range v.repairMovetext(g) {
+		if line != movetext[i] {
+			edits = append(edits, lineEdit(g.MovetextLine()+i, movetext[i], line))
+		}
+	}
+	if len(edits) > 0 {
+		fixes = append(fixes, Fix{
+			Title: "Balance comment braces and variation parentheses",
+			Line:  g.MovetextLine(), EndLine: g.MovetextLine() + len(movetext) - 1,
+			Rules: []string{RuleParentheses, RuleBraces, RuleNesting},
+			Edits: edits,
+		})
+	}
+
+	tree := g.MoveTree()
+	return append(fixes, sanFixes(tree.Moves, tree.Start)...)
+}
+
+// sanFixes returns the corrections of moves not written in canonical SAN in a line of play
+// starting from position before, and in its variations
+func sanFixes(moves []*MoveNode, before *Position) []Fix {
+	fixes := []Fix{}
+	for _, node := range moves {
+		for _, variation := range node.Variations {
+			fixes = append(fixes, sanFixes(variation, before)...)
+		}
+		if node.Position == nil {
+			break
+		}
+		if san := before.SAN(node.Move); san != node.SAN {
+			fixes = append(fixes, Fix{
+				Title: fmt.Sprintf("Replace '%s' with '%s'", node.SAN, san),
+				Line:  node.Line, EndLine: node.Line,
+				Rules: []string{RuleMoveNotation},
+				Edits: []Edit{{Line: node.Line, Col: node.Col, EndCol: node.Col + len(node.SAN), NewText: san}},
+			})
+		}
+		before = node.Position
+	}
+	return fixes
+}
+
+// lineEdit returns the edit turning line into corrected, replacing only the text that differs.
+// The replaced text starts and ends on character boundaries.
+func lineEdit(lineNumber int, line, corrected string) Edit {
+	runeStart := func(s string, i int) bool {
+		return i >= len(s) || utf8.RuneStart(s[i])
+	}
+	prefix := 0
+	for prefix < len(line) && prefix < len(corrected) && line[prefix] == corrected[prefix] {
+		prefix++
+	}
+	for !runeStart(line, prefix) || !runeStart(corrected, prefix) {
+		prefix--
+	}
+	suffix := 0
+	for suffix < len(line)-prefix && suffix < len(corrected)-prefix &&
+		line[len(line)-1-suffix] == corrected[len(corrected)-1-suffix] {
+		suffix++
+	}
+	for !runeStart(line, len(line)-suffix) || !runeStart(corrected, len(corrected)-suffix) {
+		suffix--
+	}
+	return Edit{Line: lineNumber, Col: prefix, EndCol: len(line) - suffix, NewText: corrected[prefix : len(corrected)-suffix]}
+}
@@ -0,0 +1,70 @@
+package pgn
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFixes(t *testing.T) {
+	content := `[Event "Test"]
+[Date "2024-01-15"]
+[EventDate "2024.01.14"]
+[Result "*"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Bc6 (4. Ba4 Nf6 5. 0-0) dc6 {Exchange *
+`
+	var games []*Game
+	ReadGames(context.Background(), strings.NewReader(content), func(g *Game) error {
+		games = append(games, g)
+		return nil
+	})
+
+	fixes := NewPGNValidator().Fixes(games[0])
+	titles := []string{}
+	for _, fix := range fixes {
+		titles = append(titles, fix.Title)
+	}
+	expected := []string{
+		"Correct date to 2024.01.15",
+		"Balance comment braces and variation parentheses",
+		"Replace '0-0' with 'O-O'",
+		"Replace 'Bc6' with 'Bxc6'",
+		"Replace 'dc6' with 'dxc6'",
+	}
+	if !reflect.DeepEqual(titles, expected) {
+		t.Fatalf("Expected fixes %q, got %q", expected, titles)
+	}
+
+	if edit := fixes[0].Edits[0]; edit != (Edit{Line: 2, Col: 11, EndCol: 15, NewText: ".01."}) {
+		t.Errorf("Unexpected date edit %+v", edit)
+	}
+	if edits := fixes[1].Edits; len(edits) != 1 || edits[0].Line != 6 || edits[0].NewText != "}" {
+		t.Errorf("Expected the comment to be closed on line 6, got %+v", edits)
+	}
+	line := strings.Split(content, "\n")[5]
+	if edit := fixes[4].Edits[0]; line[edit.Col:edit.EndCol] != "dc6" || edit.NewText != "dxc6" {
+		t.Errorf("Expected 'dc6' to be replaced, got %+v", edit)
+	}
+	if fix := fixes[2]; fix.Rules[0] != RuleMoveNotation || fix.Line != 6 {
+		t.Errorf("Unexpected castling fix %+v", fix)
+	}
+}
+
+func TestLineEdit(t *testing.T) {
+	tests := []struct {
+		line, corrected string
+		expected        Edit
+	}{
+		{"[Date \"2024-01-15\"]", "[Date \"2024.01.15\"]", Edit{Line: 1, Col: 11, EndCol: 15, NewText: ".01."}},
+		{"1. e4 {Good", "1. e4 {Good}", Edit{Line: 1, Col: 11, EndCol: 11, NewText: "}"}},
+		{"{é} a)", "{é} a", Edit{Line: 1, Col: 6, EndCol: 7, NewText: ""}},
+		{"{é}", "{è}", Edit{Line: 1, Col: 1, EndCol: 3, NewText: "è"}},
+	}
+	for _, tt := range tests {
+		if edit := lineEdit(1, tt.line, tt.corrected); edit != tt.expected {
+			t.Errorf("lineEdit(%q, %q) = %+v, expected %+v", tt.line, tt.corrected, edit, tt.expected)
+		}
+	}
+}
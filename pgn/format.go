@@ -0,0 +1,354 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// maxLineLength is the column at which export format movetext is wrapped
+const maxLineLength = 80
+
+// strTags is the Seven Tag Roster, in the order required by the PGN export format
+var strTags = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// strDefaults holds the values used for missing Seven Tag Roster tags
+var strDefaults = map[string]string{
+	"Event":  "?",
+	"Site":   "?",
+	"Date":   "????.??.??",
+	"Round":  "?",
+	"White":  "?",
+	"Black":  "?",
+	"Result": "*",
+}
+
+// normalizeResult maps common spellings of a game result to a PGN termination marker.
+// It returns "" if the text is not a recognizable result.
+func normalizeResult(result string) string {
+	result = strings.ReplaceAll(strings.TrimSpace(result), " ", "")
+	switch result {
+	case "1-0", "0-1", "1/2-1/2", "*":
+		return result
+	case "1:0":
+		return "1-0"
+	case "0:1":
+		return "0-1"
+	case "½-½", "½:½", "1/2", "0.5-0.5", "1/2:1/2":
+		return "1/2-1/2"
+	}
+	return ""
+}
+
+// normalizeCastling rewrites castling written with zeros to the SAN letter O form
+func normalizeCastling(move string) string {
+	core := strings.TrimRight(move, "+#")
+	switch core {
+	case "0-0":
+		return "O-O" + move[len(core):]
+	case "0-0-0":
+		return "O-O-O" + move[len(core):]
+	}
+	return move
+}
+
+// gameStartPosition returns the initial position of a game, honoring the FEN tag.
+// It returns nil if the FEN tag cannot be parsed.
+func gameStartPosition(g *Game) *Position {
+	if fen := g.Tag("FEN"); fen != "" {
+		pos, err := ParseFEN(fen)
+		if err != nil {
+			return nil
+		}
+		return pos
+	}
+	return NewStartPosition()
+}
+
+// formatLine tracks one line of play (mainline or variation) while formatting
+type formatLine struct {
+	pos        *Position // position before the next move, nil once replay has failed
+	prev       *Position // position before the last move, used to start variations
+	ply        int       // ply number of the next move
+	moves      int       // moves played so far on this line
+	needNumber bool      // next Black move needs a "N..." move number
+}
+
+// formatGame renders a single game in PGN export format
+func (v *PGNValidator) formatGame(g *Game) string {
+	var sb strings.Builder
+
+	// Tag section: Seven Tag Roster first, then the remaining tags in ASCII order
+	values := map[string]string{}
+	names := map[string]string{}
+	for _, tag := range g.Tags {
+		key := strings.ToLower(tag.Name)
+		if _, exists := values[key]; exists {
+			continue
+		}
+		values[key] = tag.Value
+		names[key] = tag.Name
+	}
+
+	for _, key := range []string{"date", "eventdate"} {
+		if value, ok := values[key]; ok && !correctDatePattern.MatchString(value) && !wildcardDatePattern.MatchString(value) {
+			if correctedDate, err := v.tryFixDate(value); err == nil {
+				values[key] = correctedDate
+			}
+		}
+	}
+	for _, key := range []string{"white", "black"} {
+		if value, ok := values[key]; ok {
+			values[key] = v.normalizePlayerName(value)
+		}
+	}
+
+	movetext, termination := v.formatMovetext(g)
+
+	result := normalizeResult(values["result"])
+	if result == "" {
+		result = termination
+	}
+	if result == "" {
+		result = "*"
+	}
+	values["result"] = result
+
+	for _, name := range strTags {
+		key := strings.ToLower(name)
+		value, ok := values[key]
+		if !ok {
+			value = strDefaults[name]
+		}
+		fmt.Fprintf(&sb, "[%s \"%s\"]\n", name, value)
+		delete(values, key)
+	}
+
+	others := make([]string, 0, len(values))
+	for key := range values {
+		others = append(others, names[key])
+	}
+	sort.Strings(others)
+	for _, name := range others {
+		fmt.Fprintf(&sb, "[%s \"%s\"]\n", name, values[strings.ToLower(name)])
+	}
+	sb.WriteString("\n")
+
+	// Movetext wrapped at maxLineLength columns
+	words := append(movetext, result)
+	lineLength := 0
+	for _, word := range words {
+		if lineLength > 0 && lineLength+1+len(word) > maxLineLength {
+			sb.WriteString("\n")
+			lineLength = 0
+		}
+		if lineLength > 0 {
+			sb.WriteString(" ")
+			lineLength++
+		}
+		sb.WriteString(word)
+		lineLength += len(word)
+	}
+	sb.WriteString("\n\n")
+
+	return sb.String()
+}
+
+// formatMovetext replays the movetext of a game and returns it as export format words,
+// together with the normalized termination marker found at the end of the mainline (or "").
+func (v *PGNValidator) formatMovetext(g *Game) ([]string, string) {
+	words := []string{}
+	openVariations := 0 // "(" waiting to be attached to the next word
+	termination := ""
+	bareMove := -1 // index of the last word if it is a move without suffix annotation
+
+	emit := func(word string) {
+		words = append(words, strings.Repeat("(", openVariations)+word)
+		openVariations = 0
+		bareMove = -1
+	}
+
+	closeVariation := func() {
+		if openVariations > 0 {
+			// Empty variation: drop it
+			openVariations--
+			return
+		}
+		if len(words) > 0 {
+			words[len(words)-1] += ")"
+			bareMove = -1
+		}
+	}
+
+	start := gameStartPosition(g)
+	startPly := 0
+	if start != nil {
+		startPly = start.Ply()
+	}
+	cur := &formatLine{pos: start, ply: startPly, needNumber: true}
+	stack := []*formatLine{}
+
+	for _, tok := range tokenizeMovetext(g.Movetext(), g.MovetextLine()) {
+		switch tok.Kind {
+		case TokenMoveNumber, TokenEscape:
+			// Move numbers are regenerated, escaped lines are not part of export format
+
+		case TokenMove, TokenUnknown:
+			if result := normalizeResult(tok.Text); result != "" {
+				if len(stack) == 0 {
+					termination = result
+				}
+				continue
+			}
+			if tok.Kind == TokenUnknown {
+				// Stray closing braces are dropped
+				if tok.Text != "}" {
+					emit(tok.Text)
+				}
+				continue
+			}
+
+			core, suffix := splitSuffixAnnotation(tok.Text)
+			san := normalizeCastling(core)
+			cur.prev = cur.pos
+			if cur.pos != nil {
+				if m, err := cur.pos.ParseSAN(core); err == nil {
+					san = cur.pos.SAN(m)
+					cur.pos = cur.pos.Play(m)
+				} else {
+					cur.pos = nil
+				}
+			}
+
+			if cur.ply%2 == 0 {
+				emit(fmt.Sprintf("%d.", cur.ply/2+1))
+			} else if cur.needNumber {
+				emit(fmt.Sprintf("%d...", cur.ply/2+1))
+			}
+			if value, ok := suffixNAGs[suffix]; ok && v.annotations == AnnotationNumeric {
+				emit(san)
+				emit(fmt.Sprintf("$%d", value))
+			} else {
+				emit(san + suffix)
+			}
+			if suffix == "" {
+				bareMove = len(words) - 1
+			}
+			cur.ply++
+			cur.moves++
+			cur.needNumber = false
+
+		case TokenNAG:
+			switch v.annotations {
+			case AnnotationNumeric:
+				if value, ok := suffixNAGs[tok.Text]; ok {
+					emit(fmt.Sprintf("$%d", value))
+					continue
+				}
+			case AnnotationSymbolic:
+				if suffix, ok := nagSuffixes[parseNAG(tok.Text)]; ok && bareMove >= 0 {
+					words[bareMove] += suffix
+					bareMove = -1
+					continue
+				}
+			}
+			emit(tok.Text)
+
+		case TokenComment, TokenLineComment:
+			text := strings.Fields(strings.ReplaceAll(tok.Text, "}", ""))
+			if len(text) == 0 {
+				emit("{}")
+			} else {
+				text[0] = "{" + text[0]
+				text[len(text)-1] += "}"
+				for _, word := range text {
+					emit(word)
+				}
+			}
+			cur.needNumber = true
+
+		case TokenVariationStart:
+			stack = append(stack, cur)
+			variation := &formatLine{pos: cur.prev, ply: cur.ply - 1, needNumber: true}
+			if cur.moves == 0 {
+				// A variation before any move has no position to start from
+				variation = &formatLine{ply: cur.ply, needNumber: true}
+			}
+			cur = variation
+			openVariations++
+
+		case TokenVariationEnd:
+			if len(stack) == 0 {
+				// Stray closing parenthesis
+				continue
+			}
+			closeVariation()
+			cur = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			cur.needNumber = true
+
+		case TokenResult:
+			if len(stack) == 0 {
+				termination = tok.Text
+			}
+		}
+	}
+
+	// Close variations left open at the end of the game
+	for range stack {
+		closeVariation()
+	}
+
+	return words, termination
+}
+
+// WriteFormattedFile reads a PGN file and writes every game to w in PGN export format
+func (v *PGNValidator) WriteFormattedFile(inputFile string, w io.Writer) error {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return fmt.Errorf("cannot open input file: %v", err)
+	}
+	defer file.Close()
+
+	return v.FormatGames(context.Background(), file, w)
+}
+
+// FormatGames reads PGN games from r and writes them to w in PGN export format. It stops with
+// ctx.Err() when ctx is canceled.
+func (v *PGNValidator) FormatGames(ctx context.Context, r io.Reader, w io.Writer) error {
+	// Increase writer buffer size to 1MB
+	writer := bufio.NewWriterSize(w, 1024*1024)
+
+	scanner := NewGameScanner(r)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		game := scanner.Game()
+
+		// Skip chunks holding neither tags nor movetext (e.g. leading blank lines)
+		if len(game.Tags) == 0 && strings.TrimSpace(strings.Join(game.Movetext(), "")) == "" {
+			continue
+		}
+
+		if _, err := writer.WriteString(v.formatGame(game)); err != nil {
+			return fmt.Errorf("error writing: %v", err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading: %v", err)
+	}
+
+	return writer.Flush()
+}
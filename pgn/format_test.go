@@ -0,0 +1,97 @@
+package pgn
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFormatGame(t *testing.T) {
+	content := `[Opening "Ruy Lopez"]
+[white "Player1"]
+[Event "Test"]
+[Date "2024-01-15"]
+[Result "1/2"]
+
+1.e4 e5 2.Nf3  Nc6 {A comment} 3.Bb5 (3.Bc4 Bc5 4.0-0) 3...a6 4.Bxc6 dc6 5.0-0 1/2
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	var out bytes.Buffer
+	validator := NewPGNValidator()
+	if err := validator.WriteFormattedFile(tmpFile, &out); err != nil {
+		t.Fatalf("WriteFormattedFile failed: %v", err)
+	}
+
+	expected := `[Event "Test"]
+[Site "?"]
+[Date "2024.01.15"]
+[Round "?"]
+[White "Player1"]
+[Black "?"]
+[Result "1/2-1/2"]
+[Opening "Ruy Lopez"]
+
+1. e4 e5 2. Nf3 Nc6 {A comment} 3. Bb5 (3. Bc4 Bc5 4. O-O) 3... a6 4. Bxc6 dxc6
+5. O-O 1/2-1/2
+
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected formatted output:\n%s\nExpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestFormatGames(t *testing.T) {
+	var out strings.Builder
+	err := NewPGNValidator().FormatGames(context.Background(), strings.NewReader("\n[White \"A\"]\n1.e4 *\n"), &out)
+	if err != nil {
+		t.Fatalf("FormatGames failed: %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "[Event \"?\"]") || !strings.Contains(out.String(), "[White \"A\"]") ||
+		!strings.HasSuffix(out.String(), "\n\n1. e4 *\n\n") {
+		t.Errorf("Unexpected formatted output:\n%s", out.String())
+	}
+}
+
+func TestFormatGameWrapsAndKeepsIllegalMoves(t *testing.T) {
+	content := `[Event "Test"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Ba4 Nf6 5. O-O Be7 6. Re1 b5 7. Bb3 d6 8. c3 O-O 9. h3 Nb8 10. Qz9 Nbd7 *
+`
+	scanner := NewGameScanner(strings.NewReader(content))
+	if !scanner.Scan() {
+		t.Fatal("Expected one game")
+	}
+
+	validator := NewPGNValidator()
+	formatted := validator.formatGame(scanner.Game())
+
+	for _, line := range strings.Split(formatted, "\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("Line longer than %d columns: %q", maxLineLength, line)
+		}
+	}
+	if !strings.Contains(formatted, "10. Qz9 Nbd7 *") {
+		t.Errorf("Expected unreplayable moves to be kept verbatim, got:\n%s", formatted)
+	}
+}
+
+func TestNormalizeResult(t *testing.T) {
+	tests := map[string]string{
+		"1-0":     "1-0",
+		"0 - 1":   "0-1",
+		"½-½":     "1/2-1/2",
+		"1/2":     "1/2-1/2",
+		"*":       "*",
+		"draw":    "",
+		"1/2-1/2": "1/2-1/2",
+	}
+	for input, expected := range tests {
+		if got := normalizeResult(input); got != expected {
+			t.Errorf("normalizeResult(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+}
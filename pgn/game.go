@@ -0,0 +1,216 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// Tag is a single PGN tag pair
+type Tag struct {
+	Name  string
+	Value string
+	Line  int
+}
+
+// Game is a chunk of a PGN file holding one game: its tag section followed by its movetext.
+// Lines covers every line of the chunk, including the blank lines separating it from the
+// next game, so concatenating the lines of all games gives back the whole file.
+type Game struct {
+	StartLine     int      // line number of Lines[0]
+	Lines         []string // lines without line terminators
+	Tags          []Tag    // well-formed tags in file order
+	MovetextStart int      // index in Lines of the first movetext line
+}
+
+// Tag returns the value of the named tag (case-insensitive), or "" if missing
+func (g *Game) Tag(name string) string {
+	for _, tag := range g.Tags {
+		if strings.EqualFold(tag.Name, name) {
+			return tag.Value
+		}
+	}
+	return ""
+}
+
+// HasTag reports whether the named tag (case-insensitive) is present
+func (g *Game) HasTag(name string) bool {
+	for _, tag := range g.Tags {
+		if strings.EqualFold(tag.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Movetext returns the lines following the tag section
+func (g *Game) Movetext() []string {
+	return g.Lines[g.MovetextStart:]
+}
+
+// MovetextLine returns the line number of the first movetext line
+func (g *Game) MovetextLine() int {
+	return g.StartLine + g.MovetextStart
+}
+
+// isTagLine reports whether a trimmed line belongs to a tag section.
+// Lines starting with "[%" are embedded commands of a multi-line comment, not tags.
+func isTagLine(line string) bool {
+	return strings.HasPrefix(line, "[") && !strings.HasPrefix(line, "[%")
+}
+
+// unescapeTagValue returns the text of a tag value as written in PGN, where \" and \\ stand
+// for a quote and a backslash
+func unescapeTagValue(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) && (value[i+1] == '"' || value[i+1] == '\\') {
+			i++
+		}
+		sb.WriteByte(value[i])
+	}
+	return sb.String()
+}
+
+// escapeTagValue returns a text as a PGN tag value, escaping quotes and backslashes
+func escapeTagValue(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text)
+}
+
+// GameScanner splits a PGN stream into games.
+// A new game starts at the first tag line following some movetext.
+type GameScanner struct {
+	scanner    *bufio.Scanner
+	lineNumber int
+	pending    *string // first line of the next game, already read
+	game       *Game
+	lineEnding string
+}
+
+// NewGameScanner creates a scanner reading games from r
+func NewGameScanner(r io.Reader) *GameScanner {
+	scanner := bufio.NewScanner(r)
+	// Increase buffer size to 1MB for better performance
+	buf := make([]byte, 1024*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	s := &GameScanner{scanner: scanner}
+	scanner.Split(s.scanLines)
+	return s
+}
+
+// scanLines splits lines like bufio.ScanLines, recording the line ending of the first line
+func (s *GameScanner) scanLines(data []byte, atEOF bool) (int, []byte, error) {
+	advance, token, err := bufio.ScanLines(data, atEOF)
+	if s.lineEnding == "" && token != nil && advance > len(token) {
+		if advance == len(token)+2 {
+			s.lineEnding = "\r\n"
+		} else {
+			s.lineEnding = "\n"
+		}
+	}
+	return advance, token, err
+}
+
+// LineEnding returns the line terminator used by the input ("\r\n" or "\n")
+func (s *GameScanner) LineEnding() string {
+	if s.lineEnding == "" {
+		return "\n"
+	}
+	return s.lineEnding
+}
+
+// Scan advances to the next game, returning false at the end of the input or on error
+func (s *GameScanner) Scan() bool {
+	game := &Game{StartLine: s.lineNumber + 1}
+	seenMovetext := false
+
+	if s.pending != nil {
+		game.StartLine = s.lineNumber
+		game.Lines = append(game.Lines, *s.pending)
+		s.pending = nil
+	}
+
+	for s.scanner.Scan() {
+		s.lineNumber++
+		line := s.scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if isTagLine(trimmed) {
+			if seenMovetext {
+				s.pending = &line
+				break
+			}
+		} else if trimmed != "" {
+			seenMovetext = true
+		}
+		game.Lines = append(game.Lines, line)
+	}
+
+	if len(game.Lines) == 0 {
+		s.game = nil
+		return false
+	}
+
+	game.parseTags()
+	s.game = game
+	return true
+}
+
+// Game returns the game read by the last call to Scan
+func (s *GameScanner) Game() *Game {
+	return s.game
+}
+
+// Err returns the first read error encountered, if any
+func (s *GameScanner) Err() error {
+	return s.scanner.Err()
+}
+
+// ReadGames reads the games of a PGN stream, calling visit for each game holding tags or
+// movetext. It stops at the first error returned by visit, or with ctx.Err() when ctx is
+// canceled.
+func ReadGames(ctx context.Context, r io.Reader, visit func(*Game) error) error {
+	scanner := NewGameScanner(r)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		game := scanner.Game()
+		if !hasContent(game) {
+			continue
+		}
+		if err := visit(game); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// parseTags fills Tags and MovetextStart from the leading tag lines
+func (g *Game) parseTags() {
+	g.MovetextStart = len(g.Lines)
+	for i, line := range g.Lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		if !isTagLine(trimmed) {
+			g.MovetextStart = i
+			return
+		}
+		if matches := tagPattern.FindStringSubmatch(trimmed); matches != nil {
+			g.Tags = append(g.Tags, Tag{Name: matches[1], Value: matches[2], Line: g.StartLine + i})
+		}
+	}
+}
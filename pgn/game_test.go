@@ -0,0 +1,114 @@
+package pgn
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestGameScanner(t *testing.T) {
+	content := `[Event "First"]
+[White "A"]
+
+1. e4 e5 1-0
+
+[Event "Second"]
+[black "B"]
+
+1. d4 { [%clk 0:01:00]
+[%clk 0:00:59] } d5 *
+`
+	scanner := NewGameScanner(strings.NewReader(content))
+
+	var games []*Game
+	for scanner.Scan() {
+		games = append(games, scanner.Game())
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(games) != 2 {
+		t.Fatalf("Expected 2 games, got %d", len(games))
+	}
+
+	first, second := games[0], games[1]
+	if first.StartLine != 1 || second.StartLine != 6 {
+		t.Errorf("Expected games to start on lines 1 and 6, got %d and %d", first.StartLine, second.StartLine)
+	}
+	if first.Tag("event") != "First" || second.Tag("Black") != "B" {
+		t.Errorf("Unexpected tag values: %q, %q", first.Tag("event"), second.Tag("Black"))
+	}
+	if second.MovetextLine() != 9 || len(second.Movetext()) != 2 {
+		t.Errorf("Expected second movetext on line 9 with 2 lines, got line %d with %d lines",
+			second.MovetextLine(), len(second.Movetext()))
+	}
+	if len(first.Lines)+len(second.Lines) != 10 {
+		t.Errorf("Expected games to cover all 10 lines, got %d", len(first.Lines)+len(second.Lines))
+	}
+}
+
+func TestReadGames(t *testing.T) {
+	content := "\n\n[Event \"First\"]\n\n1. e4 *\n\n[Event \"Second\"]\n\n1. d4 *\n"
+
+	var events []string
+	err := ReadGames(context.Background(), strings.NewReader(content), func(g *Game) error {
+		events = append(events, g.Tag("Event"))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadGames failed: %v", err)
+	}
+	if strings.Join(events, ",") != "First,Second" {
+		t.Errorf("Expected games First and Second, got %v", events)
+	}
+
+	// Canceling the context stops reading
+	ctx, cancel := context.WithCancel(context.Background())
+	count := 0
+	err = ReadGames(ctx, strings.NewReader(content), func(g *Game) error {
+		count++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || count != 1 {
+		t.Errorf("Expected cancellation after 1 game, got %v after %d games", err, count)
+	}
+}
+
+func TestTokenizeMovetext(t *testing.T) {
+	lines := []string{
+		"1. e4 $1 e5!? {a (comment)",
+		"on two lines} 2.Nf3 (2. Nc3) ; rest",
+		"% escaped",
+		"2... Nc6 1/2-1/2",
+	}
+
+	kinds := []TokenKind{
+		TokenMoveNumber, TokenMove, TokenNAG, TokenMove, TokenComment,
+		TokenMoveNumber, TokenMove, TokenVariationStart, TokenMoveNumber, TokenMove, TokenVariationEnd, TokenLineComment,
+		TokenEscape,
+		TokenMoveNumber, TokenMove, TokenResult,
+	}
+
+	tokens := tokenizeMovetext(lines, 10)
+	if len(tokens) != len(kinds) {
+		t.Fatalf("Expected %d tokens, got %d: %v", len(kinds), len(tokens), tokens)
+	}
+	for i, kind := range kinds {
+		if tokens[i].Kind != kind {
+			t.Errorf("Token %d (%q): expected kind %d, got %d", i, tokens[i].Text, kind, tokens[i].Kind)
+		}
+	}
+
+	comment := tokens[4]
+	if comment.Text != "a (comment)\non two lines" || comment.Line != 10 || comment.EndLine != 11 {
+		t.Errorf("Unexpected comment token: %+v", comment)
+	}
+
+	unterminated := tokenizeMovetext([]string{"1. e4 {open"}, 1)
+	if last := unterminated[len(unterminated)-1]; last.Kind != TokenComment || !last.Unterminated {
+		t.Errorf("Expected unterminated comment token, got %+v", last)
+	}
+}
@@ -0,0 +1,289 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// JSONGame is the JSON representation of a game, as written by the export command
+type JSONGame struct {
+	Tags     map[string]string `json:"tags"`               // tag values without PGN escapes; the first of duplicate tags wins
+	StartFEN string            `json:"start_fen"`          // initial position, "" if the FEN tag is invalid
+	Comments []string          `json:"comments,omitempty"` // comments preceding the first move
+	Commands []Command         `json:"commands,omitempty"` // embedded commands of those comments
+	Moves    []JSONMove        `json:"moves"`              // mainline
+	Result   string            `json:"result"`             // game termination marker
+}
+
+// JSONMove is a move of a JSONGame
+type JSONMove struct {
+	Ply            int          `json:"ply"`                       // 1 for White's first move of a game from the standard position
+	SAN            string       `json:"san"`                       // move in SAN, as written if it cannot be replayed
+	UCI            string       `json:"uci,omitempty"`             // move in UCI notation, missing if it cannot be replayed
+	FEN            string       `json:"fen,omitempty"`             // position after the move, missing if it cannot be replayed
+	NAGs           []string     `json:"nags,omitempty"`            // "$14" or suffix annotations such as "!?"
+	CommentsBefore []string     `json:"comments_before,omitempty"` // comments preceding the first move of a variation
+	Comments       []string     `json:"comments,omitempty"`        // comments following the move, without embedded commands
+	Commands       []Command    `json:"commands,omitempty"`        // embedded commands of the comments following the move
+	Clock          string       `json:"clock,omitempty"`           // value of the [%clk] command
+	Eval           string       `json:"eval,omitempty"`            // value of the [%eval] command
+	Variations     [][]JSONMove `json:"variations,omitempty"`      // alternatives to this move
+}
+
+// NewJSONGame converts a game to its JSON representation, replaying its moves
+func NewJSONGame(g *Game) *JSONGame {
+	tree := g.MoveTree()
+	game := &JSONGame{
+		Tags:     make(map[string]string),
+		Comments: tree.Comments,
+		Commands: tree.Commands,
+		Moves:    jsonMoves(tree.Moves, tree.Start),
+		Result:   gameTermination(g),
+	}
+	for _, tag := range g.Tags {
+		if _, exists := game.Tags[tag.Name]; !exists {
+			game.Tags[tag.Name] = unescapeTagValue(tag.Value)
+		}
+	}
+	if tree.Start != nil {
+		game.StartFEN = tree.Start.FEN()
+	}
+	return game
+}
+
+// jsonMoves converts a line of play starting from position before (nil if unknown)
+func jsonMoves(nodes []*MoveNode, before *Position) []JSONMove {
+	moves := make([]JSONMove, 0, len(nodes))
+	for _, node := range nodes {
+		move := JSONMove{
+			Ply:            node.Ply + 1,
+			SAN:            node.SAN,
+			NAGs:           node.NAGs,
+			CommentsBefore: node.CommentsBefore,
+			Comments:       node.Comments,
+			Commands:       node.Commands,
+		}
+		if node.Position != nil && before != nil {
+			move.SAN = before.SAN(node.Move)
+			move.UCI = node.Move.UCI()
+			move.FEN = node.Position.FEN()
+		}
+		for _, c := range node.Commands {
+			switch c.Name {
+			case "clk":
+				move.Clock = c.Value
+			case "eval":
+				move.Eval = c.Value
+			}
+		}
+		for _, variation := range node.Variations {
+			move.Variations = append(move.Variations, jsonMoves(variation, before))
+		}
+		moves = append(moves, move)
+		before = node.Position
+	}
+	return moves
+}
+
+// WriteJSONFiles writes every game of the input files to w as JSON: an array of games, or
+// one game per line if ndjson is set. It returns the number of games written.
+func WriteJSONFiles(inputFiles []string, w io.Writer, ndjson bool) (int, error) {
+	// Increase writer buffer size to 1MB
+	writer := bufio.NewWriterSize(w, 1024*1024)
+	written := 0
+
+	if !ndjson {
+		writer.WriteString("[")
+	}
+	for _, filename := range inputFiles {
+		file, err := os.Open(filename)
+		if err != nil {
+			return written, fmt.Errorf("cannot open input file: %v", err)
+		}
+
+		scanner := NewGameScanner(file)
+		for scanner.Scan() {
+			game := scanner.Game()
+			if !hasContent(game) {
+				continue
+			}
+
+			var data []byte
+			if ndjson {
+				data, err = json.Marshal(NewJSONGame(game))
+			} else {
+				data, err = json.MarshalIndent(NewJSONGame(game), "  ", "  ")
+				separator := ",\n  "
+				if written == 0 {
+					separator = "\n  "
+				}
+				writer.WriteString(separator)
+			}
+			if err != nil {
+				file.Close()
+				return written, fmt.Errorf("cannot encode game: %v", err)
+			}
+			writer.Write(data)
+			if ndjson {
+				writer.WriteString("\n")
+			}
+			written++
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return written, fmt.Errorf("error reading: %v", err)
+		}
+	}
+	if !ndjson {
+		writer.WriteString("\n]\n")
+	}
+
+	return written, writer.Flush()
+}
+
+// jsonMovetext renders moves as PGN movetext words. Move numbers are left out: the
+// formatter regenerates them.
+func jsonMovetext(words []string, moves []JSONMove) []string {
+	for _, move := range moves {
+		words = jsonComments(words, move.CommentsBefore, nil)
+
+		nags := move.NAGs
+		if len(nags) > 0 && !strings.HasPrefix(nags[0], "$") {
+			// Suffix annotation written with the move
+			words = append(words, move.SAN+nags[0])
+			nags = nags[1:]
+		} else {
+			words = append(words, move.SAN)
+		}
+		words = append(words, nags...)
+
+		commands := move.Commands
+		// Clock and evaluation given without their commands
+		if move.Clock != "" && !hasCommand(commands, "clk") {
+			commands = append(commands, Command{Name: "clk", Value: move.Clock})
+		}
+		if move.Eval != "" && !hasCommand(commands, "eval") {
+			commands = append(commands, Command{Name: "eval", Value: move.Eval})
+		}
+		words = jsonComments(words, move.Comments, commands)
+
+		for _, variation := range move.Variations {
+			words = append(words, "(")
+			words = jsonMovetext(words, variation)
+			words = append(words, ")")
+		}
+	}
+	return words
+}
+
+// jsonComments renders comments as PGN comment words. The embedded commands go at the start
+// of the first comment.
+func jsonComments(words []string, comments []string, commands []Command) []string {
+	var parts []string
+	for _, c := range commands {
+		parts = append(parts, c.String())
+	}
+	if len(comments) == 0 && len(parts) > 0 {
+		comments = []string{""}
+	}
+	for i, text := range comments {
+		if i == 0 && len(parts) > 0 {
+			text = strings.TrimSpace(strings.Join(parts, " ") + " " + text)
+		}
+		words = append(words, "{"+strings.ReplaceAll(text, "}", "")+"}")
+	}
+	return words
+}
+
+// hasCommand reports whether commands include one with the given name
+func hasCommand(commands []Command, name string) bool {
+	for _, c := range commands {
+		if c.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Game converts the JSON representation back to a game
+func (jg *JSONGame) Game() *Game {
+	names := make([]string, 0, len(jg.Tags))
+	for name := range jg.Tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names)+2)
+	for _, name := range names {
+		lines = append(lines, fmt.Sprintf("[%s \"%s\"]", name, escapeTagValue(jg.Tags[name])))
+	}
+	lines = append(lines, "")
+
+	words := jsonComments(nil, jg.Comments, jg.Commands)
+	words = jsonMovetext(words, jg.Moves)
+	if jg.Result != "" {
+		words = append(words, jg.Result)
+	}
+	lines = append(lines, strings.Join(words, " "))
+
+	game := &Game{StartLine: 1, Lines: lines}
+	game.parseTags()
+	return game
+}
+
+// ImportJSON reads games written by WriteJSONFiles, as an array or one per line, and writes
+// them to w in PGN export format. It returns the number of games written.
+func (v *PGNValidator) ImportJSON(r io.Reader, w io.Writer) (int, error) {
+	reader := bufio.NewReader(r)
+	decoder := json.NewDecoder(reader)
+	writer := bufio.NewWriterSize(w, 1024*1024)
+	written := 0
+
+	// An array of games or a stream of games
+	array := false
+	for {
+		b, err := reader.Peek(1)
+		if err != nil {
+			break
+		}
+		if b[0] == ' ' || b[0] == '\t' || b[0] == '\r' || b[0] == '\n' {
+			reader.ReadByte()
+			continue
+		}
+		array = b[0] == '['
+		break
+	}
+	if array {
+		if _, err := decoder.Token(); err != nil {
+			return written, fmt.Errorf("invalid JSON: %v", err)
+		}
+	}
+
+	for array && decoder.More() || !array {
+		var game JSONGame
+		if err := decoder.Decode(&game); err != nil {
+			if err == io.EOF && !array {
+				break
+			}
+			return written, fmt.Errorf("invalid JSON game %d: %v", written+1, err)
+		}
+		if _, err := writer.WriteString(v.formatGame(game.Game())); err != nil {
+			return written, fmt.Errorf("error writing: %v", err)
+		}
+		written++
+	}
+
+	return written, writer.Flush()
+}
@@ -0,0 +1,156 @@
+package pgn
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestJSONGame(t *testing.T) {
+	game := &Game{Lines: []string{
+		`[Event "Blitz"]`,
+		`[Result "1-0"]`,
+		"",
+		"{ [%evp 0,10] Opening } 1. e4!? { [%clk 0:03:00] [%eval 0.3] good } 1... e5 $1",
+		"( 1... c5 { Sicilian } 2. Nf3 ) 2. Qh5 1-0",
+	}, MovetextStart: 3}
+	game.parseTags()
+
+	jg := NewJSONGame(game)
+	if jg.Tags["Event"] != "Blitz" || jg.Result != "1-0" || jg.StartFEN != StartFEN {
+		t.Errorf("Unexpected tags, result or start position: %+v", jg)
+	}
+	if len(jg.Comments) != 1 || jg.Comments[0] != "Opening" || len(jg.Commands) != 1 || jg.Commands[0].Name != "evp" {
+		t.Errorf("Unexpected comments before the first move: %v %v", jg.Comments, jg.Commands)
+	}
+	if len(jg.Moves) != 3 {
+		t.Fatalf("Expected 3 mainline moves, got %d", len(jg.Moves))
+	}
+	e4 := jg.Moves[0]
+	if e4.Ply != 1 || e4.SAN != "e4" || e4.UCI != "e2e4" || e4.NAGs[0] != "!?" || e4.Clock != "0:03:00" || e4.Eval != "0.3" || e4.Comments[0] != "good" {
+		t.Errorf("Unexpected first move %+v", e4)
+	}
+	e5 := jg.Moves[1]
+	if len(e5.Variations) != 1 || len(e5.Variations[0]) != 2 || e5.Variations[0][1].FEN != "rnbqkbnr/pp1ppppp/8/2p5/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2" {
+		t.Errorf("Unexpected variation %+v", e5.Variations)
+	}
+
+	// The JSON representation converts back to the formatted game
+	data, err := json.Marshal(jg)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded JSONGame
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	validator := NewPGNValidator()
+	if got, expected := validator.formatGame(decoded.Game()), validator.formatGame(game); got != expected {
+		t.Errorf("Round trip mismatch:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestExportImportJSON(t *testing.T) {
+	content := `[Event "Open"]
+[White "A"]
+[Black "B"]
+[Result "1/2-1/2"]
+
+1. d4 d5 2. c4 { [%clk 1:30:00] } 2... e6 1/2-1/2
+
+[Event "Open"]
+[White "C"]
+[Black "D"]
+[Result "*"]
+
+1. e4 *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	var formatted bytes.Buffer
+	if err := NewPGNValidator().WriteFormattedFile(tmpFile, &formatted); err != nil {
+		t.Fatalf("WriteFormattedFile failed: %v", err)
+	}
+
+	for _, ndjson := range []bool{false, true} {
+		var exported bytes.Buffer
+		written, err := WriteJSONFiles([]string{tmpFile}, &exported, ndjson)
+		if err != nil || written != 2 {
+			t.Fatalf("WriteJSONFiles: expected 2 games, got %d (error %v)", written, err)
+		}
+		if ndjson && strings.Count(exported.String(), "\n") != 2 {
+			t.Errorf("Expected one game per line, got:\n%s", exported.String())
+		}
+		if !ndjson && !json.Valid(exported.Bytes()) {
+			t.Errorf("Expected a valid JSON array, got:\n%s", exported.String())
+		}
+
+		var imported bytes.Buffer
+		written, err = NewPGNValidator().ImportJSON(&exported, &imported)
+		if err != nil || written != 2 {
+			t.Fatalf("ImportJSON: expected 2 games, got %d (error %v)", written, err)
+		}
+		if imported.String() != formatted.String() {
+			t.Errorf("ndjson=%v: imported games differ:\n%s\nexpected:\n%s", ndjson, imported.String(), formatted.String())
+		}
+	}
+}
+
+func TestImportJSONMinimal(t *testing.T) {
+	input := `{"tags": {"White": "A", "Black": "B"}, "moves": [{"san": "e4", "clock": "0:05:00"}, {"san": "e5"}], "result": "*"}
+{"tags": {}, "moves": [], "result": "1-0"}
+`
+	var out bytes.Buffer
+	written, err := NewPGNValidator().ImportJSON(strings.NewReader(input), &out)
+	if err != nil || written != 2 {
+		t.Fatalf("Expected 2 games, got %d (error %v)", written, err)
+	}
+	if !strings.Contains(out.String(), "1. e4 {[%clk 0:05:00]} 1... e5 *") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+
+	if _, err := NewPGNValidator().ImportJSON(strings.NewReader(`[{"tags": 1}]`), &out); err == nil {
+		t.Errorf("Expected an error for invalid JSON")
+	}
+}
+
+func TestJSONTagEscapes(t *testing.T) {
+	content := `[Event "A \"B\" C"]
+[Site "C:\\Games"]
+[Result "*"]
+
+1. e4 *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	// Exported values are plain text
+	var exported bytes.Buffer
+	if _, err := WriteJSONFiles([]string{tmpFile}, &exported, true); err != nil {
+		t.Fatalf("WriteJSONFiles failed: %v", err)
+	}
+	var jg JSONGame
+	if err := json.Unmarshal(exported.Bytes(), &jg); err != nil {
+		t.Fatalf("Invalid JSON %s: %v", exported.String(), err)
+	}
+	if jg.Tags["Event"] != `A "B" C` || jg.Tags["Site"] != `C:\Games` {
+		t.Errorf("Expected unescaped tag values, got %q", jg.Tags)
+	}
+
+	// Imported values are escaped again, giving back the original tags
+	var imported bytes.Buffer
+	if _, err := NewPGNValidator().ImportJSON(&exported, &imported); err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	for _, tag := range []string{`[Event "A \"B\" C"]`, `[Site "C:\\Games"]`} {
+		if !strings.Contains(imported.String(), tag) {
+			t.Errorf("Expected %s in:\n%s", tag, imported.String())
+		}
+	}
+	if errors := NewPGNValidator().ValidateString(imported.String()); len(errors) != 0 {
+		t.Errorf("Expected the imported game to be valid, got %v", errors)
+	}
+}
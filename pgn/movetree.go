@@ -0,0 +1,169 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"strings"
+)
+
+// MoveNode is a move of a game together with the annotations that follow it
+type MoveNode struct {
+	SAN            string        // move as written, without suffix annotations
+	Move           Move          // replayed move, valid only if Position is not nil
+	Position       *Position     // position after the move, nil if the move could not be replayed
+	Ply            int           // ply of the move: 0 for White's first move
+	Line           int           // line number of the move
+	Col, EndCol    int           // byte offsets of the move in its line, EndCol past its suffix annotation
+	NAGs           []string      // annotations in file order: "$14" or suffixes such as "!?"
+	CommentsBefore []string      // comments preceding the first move of a variation
+	Comments       []string      // comments following the move, without embedded commands
+	Commands       []Command     // embedded commands of the comments following the move
+	Variations     [][]*MoveNode // alternatives to this move
+}
+
+// MoveNumber returns the move number of the move (1 for the first move of each side)
+func (n *MoveNode) MoveNumber() int {
+	return n.Ply/2 + 1
+}
+
+// Color returns the side making the move
+func (n *MoveNode) Color() Color {
+	if n.Ply%2 == 0 {
+		return White
+	}
+	return Black
+}
+
+// MoveTree is the parsed movetext of a game
+type MoveTree struct {
+	Start    *Position   // initial position, nil if the FEN tag is invalid
+	Comments []string    // comments preceding the first move
+	Commands []Command   // embedded commands of those comments
+	Moves    []*MoveNode // mainline moves
+	Result   string      // game termination marker, "" if missing
+}
+
+// treeLine tracks one line of play while building a move tree
+type treeLine struct {
+	moves   []*MoveNode
+	pos     *Position // position before the next move, nil once replay has failed
+	prev    *Position // position before the last move
+	ply     int       // ply of the next move
+	pending []string  // comments waiting for the first move of a variation
+	parent  *MoveNode // move the variation is an alternative to, nil for the mainline
+}
+
+// MoveTree parses the movetext of a game into its mainline and variations, replaying the
+// moves from the initial position. Moves that cannot be replayed are kept with a nil Position.
+func (g *Game) MoveTree() *MoveTree {
+	tree := &MoveTree{Start: gameStartPosition(g)}
+
+	startPly := 0
+	if tree.Start != nil {
+		startPly = tree.Start.Ply()
+	}
+	cur := &treeLine{pos: tree.Start, ply: startPly}
+	stack := []*treeLine{}
+
+	closeVariation := func() {
+		if cur.parent != nil && len(cur.moves) > 0 {
+			cur.parent.Variations = append(cur.parent.Variations, cur.moves)
+		}
+		cur = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+	}
+
+	for _, tok := range tokenizeMovetext(g.Movetext(), g.MovetextLine()) {
+		var last *MoveNode
+		if len(cur.moves) > 0 {
+			last = cur.moves[len(cur.moves)-1]
+		}
+
+		switch tok.Kind {
+		case TokenMove:
+			core, suffix := splitSuffixAnnotation(tok.Text)
+			node := &MoveNode{SAN: core, Ply: cur.ply, Line: tok.Line, Col: tok.Col, EndCol: tok.EndCol, CommentsBefore: cur.pending}
+			cur.pending = nil
+			if suffix != "" {
+				node.NAGs = append(node.NAGs, suffix)
+			}
+
+			cur.prev = cur.pos
+			if cur.pos != nil {
+				if m, err := cur.pos.ParseSAN(core); err == nil {
+					cur.pos = cur.pos.Play(m)
+					node.Move, node.Position = m, cur.pos
+				} else {
+					cur.pos = nil
+				}
+			}
+			cur.moves = append(cur.moves, node)
+			cur.ply++
+
+		case TokenNAG:
+			if last != nil {
+				last.NAGs = append(last.NAGs, tok.Text)
+			}
+
+		case TokenComment, TokenLineComment:
+			text, commands := parseCommands(tok.Text, tok.Line)
+			switch {
+			case last != nil:
+				last.Commands = append(last.Commands, commands...)
+				if text != "" {
+					last.Comments = append(last.Comments, text)
+				}
+			case len(stack) == 0:
+				tree.Commands = append(tree.Commands, commands...)
+				if text != "" {
+					tree.Comments = append(tree.Comments, text)
+				}
+			case text != "":
+				cur.pending = append(cur.pending, text)
+			}
+
+		case TokenVariationStart:
+			stack = append(stack, cur)
+			variation := &treeLine{parent: last}
+			if last != nil {
+				variation.pos, variation.ply = cur.prev, last.Ply
+			} else {
+				// A variation before any move has no move to be an alternative to
+				variation.ply = cur.ply
+			}
+			cur = variation
+
+		case TokenVariationEnd:
+			if len(stack) > 0 {
+				closeVariation()
+			}
+
+		case TokenResult:
+			if len(stack) == 0 {
+				tree.Result = tok.Text
+			}
+		}
+	}
+
+	// Close variations left open at the end of the game
+	for len(stack) > 0 {
+		closeVariation()
+	}
+
+	tree.Moves = cur.moves
+	return tree
+}
+
+// hasCommands reports whether the movetext of a game may hold embedded commands
+func hasCommands(g *Game) bool {
+	for _, line := range g.Movetext() {
+		if strings.Contains(line, "[%") {
+			return true
+		}
+	}
+	return false
+}
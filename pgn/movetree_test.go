@@ -0,0 +1,77 @@
+package pgn
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMoveTree(t *testing.T) {
+	content := `[Event "Test"]
+
+{Opening [%evp 0,20]} 1. e4! { [%clk 0:03:00] [%eval 0.34] Best by test } 1... e5 $1
+2. Nf3 (2. f4 {King's Gambit} exf4 (2... d5)) (2. Qh5?!) 2... Nc6 3. Bb5 Xx9 4. O-O *
+`
+	scanner := NewGameScanner(strings.NewReader(content))
+	if !scanner.Scan() {
+		t.Fatal("Expected a game")
+	}
+	tree := scanner.Game().MoveTree()
+
+	if tree.Result != "*" {
+		t.Errorf("Expected result '*', got '%s'", tree.Result)
+	}
+	if !reflect.DeepEqual(tree.Comments, []string{"Opening"}) || len(tree.Commands) != 1 {
+		t.Errorf("Unexpected game comments %q and commands %v", tree.Comments, tree.Commands)
+	}
+
+	var mainline []string
+	for _, node := range tree.Moves {
+		mainline = append(mainline, node.SAN)
+	}
+	if expected := []string{"e4", "e5", "Nf3", "Nc6", "Bb5", "Xx9", "O-O"}; !reflect.DeepEqual(mainline, expected) {
+		t.Fatalf("Expected mainline %v, got %v", expected, mainline)
+	}
+
+	e4 := tree.Moves[0]
+	if !reflect.DeepEqual(e4.NAGs, []string{"!"}) {
+		t.Errorf("Expected NAGs [!], got %v", e4.NAGs)
+	}
+	if !reflect.DeepEqual(e4.Comments, []string{"Best by test"}) {
+		t.Errorf("Expected comment 'Best by test', got %q", e4.Comments)
+	}
+	expectedCommands := []Command{{Name: "clk", Value: "0:03:00", Line: 3}, {Name: "eval", Value: "0.34", Line: 3}}
+	if !reflect.DeepEqual(e4.Commands, expectedCommands) {
+		t.Errorf("Expected commands %v, got %v", expectedCommands, e4.Commands)
+	}
+	if !reflect.DeepEqual(tree.Moves[1].NAGs, []string{"$1"}) || tree.Moves[1].Color() != Black {
+		t.Errorf("Unexpected second move %+v", tree.Moves[1])
+	}
+
+	// Variations are alternatives to the move they follow and start from its position
+	nf3 := tree.Moves[2]
+	if nf3.Line != 4 || nf3.MoveNumber() != 2 || len(nf3.Variations) != 2 {
+		t.Fatalf("Unexpected Nf3 node %+v", nf3)
+	}
+	kingsGambit := nf3.Variations[0]
+	if len(kingsGambit) != 2 || kingsGambit[0].SAN != "f4" || kingsGambit[0].Position == nil {
+		t.Fatalf("Unexpected first variation %+v", kingsGambit)
+	}
+	if !reflect.DeepEqual(kingsGambit[0].Comments, []string{"King's Gambit"}) {
+		t.Errorf("Expected variation comment, got %q", kingsGambit[0].Comments)
+	}
+	if len(kingsGambit[1].Variations) != 1 || kingsGambit[1].Variations[0][0].SAN != "d5" {
+		t.Errorf("Expected nested variation 2... d5, got %+v", kingsGambit[1].Variations)
+	}
+	if nf3.Variations[1][0].SAN != "Qh5" || !reflect.DeepEqual(nf3.Variations[1][0].NAGs, []string{"?!"}) {
+		t.Errorf("Unexpected second variation %+v", nf3.Variations[1][0])
+	}
+
+	// Replay stops at the first illegal move
+	if tree.Moves[4].Position == nil {
+		t.Error("Expected Bb5 to be replayed")
+	}
+	if tree.Moves[5].Position != nil || tree.Moves[6].Position != nil {
+		t.Error("Expected moves after Xx9 not to be replayed")
+	}
+}
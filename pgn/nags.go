@@ -0,0 +1,152 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// maxStandardNAG is the highest NAG defined by the PGN standard; 140-255 are left to
+// applications (ChessBase uses $140-$146, for instance)
+const maxStandardNAG = 139
+
+// nagPattern matches a numeric annotation glyph: "$" followed by its value
+var nagPattern = regexp.MustCompile(`\$\d*`)
+
+// standaloneAnnotationPattern matches "!" and "?" annotations written apart from their move
+var standaloneAnnotationPattern = regexp.MustCompile(`(^|\s)([!?]+\s+)*[!?]+(\s|$)`)
+
+// suffixNAGs maps move suffix annotations to their NAG values
+var suffixNAGs = map[string]int{
+	"!":  1,
+	"?":  2,
+	"!!": 3,
+	"??": 4,
+	"!?": 5,
+	"?!": 6,
+}
+
+// nagSuffixes maps NAG values back to move suffix annotations
+var nagSuffixes = map[int]string{
+	1: "!",
+	2: "?",
+	3: "!!",
+	4: "??",
+	5: "!?",
+	6: "?!",
+}
+
+// AnnotationStyle selects how the corrector writes move annotations
+type AnnotationStyle int
+
+const (
+	AnnotationKeep     AnnotationStyle = iota // leave annotations as written
+	AnnotationNumeric                         // write "!?" as "$5"
+	AnnotationSymbolic                        // write "$5" as "!?" when it follows a move
+)
+
+// ParseAnnotationStyle converts a command-line value (keep, numeric, symbolic) to an AnnotationStyle
+func ParseAnnotationStyle(value string) (AnnotationStyle, error) {
+	switch value {
+	case "keep", "":
+		return AnnotationKeep, nil
+	case "numeric":
+		return AnnotationNumeric, nil
+	case "symbolic":
+		return AnnotationSymbolic, nil
+	}
+	return AnnotationKeep, fmt.Errorf("unknown annotation style '%s' (expected keep, numeric or symbolic)", value)
+}
+
+// SetAnnotationStyle sets how corrected and formatted output writes move annotations
+func (v *PGNValidator) SetAnnotationStyle(style AnnotationStyle) {
+	v.annotations = style
+}
+
+// parseNAG returns the value of a "$n" token, or -1 if it is malformed
+func parseNAG(text string) int {
+	if len(text) < 2 || text[0] != '$' {
+		return -1
+	}
+	value, err := strconv.Atoi(text[1:])
+	if err != nil {
+		return -1
+	}
+	return value
+}
+
+// validateNAGs checks the numeric annotation glyphs of a movetext line
+func (v *PGNValidator) validateNAGs(line string, lineNumber int) {
+	for _, nag := range nagPattern.FindAllString(v.removeComments(line), -1) {
+		value := parseNAG(nag)
+		switch {
+		case value < 0 || value > 255:
+			v.errors = append(v.errors, ValidationError{
+				Line:    lineNumber,
+				Message: fmt.Sprintf("Invalid NAG '%s': value must be between 0 and 255", nag),
+				Rule:    RuleNAG,
+			})
+		case value > maxStandardNAG:
+			v.errors = append(v.errors, ValidationError{
+				Line:     lineNumber,
+				Message:  fmt.Sprintf("Warning: Non-standard NAG '%s'", nag),
+				Rule:     RuleNAG,
+				Severity: SeverityWarning,
+			})
+		}
+	}
+}
+
+// convertAnnotations rewrites the move annotations of movetext lines in the selected style
+func (v *PGNValidator) convertAnnotations(lines []string) []string {
+	if v.annotations == AnnotationKeep {
+		return lines
+	}
+
+	tokens := tokenizeMovetext(lines, 0)
+
+	// Edit from the end so earlier positions stay valid
+	for i := len(tokens) - 1; i >= 0; i-- {
+		tok := tokens[i]
+		line := lines[tok.Line]
+
+		switch v.annotations {
+		case AnnotationNumeric:
+			switch tok.Kind {
+			case TokenMove:
+				_, suffix := splitSuffixAnnotation(tok.Text)
+				if value, ok := suffixNAGs[suffix]; ok {
+					at := tok.EndCol - len(suffix)
+					lines[tok.Line] = line[:at] + fmt.Sprintf(" $%d", value) + line[tok.EndCol:]
+				}
+			case TokenNAG:
+				if value, ok := suffixNAGs[tok.Text]; ok {
+					lines[tok.Line] = line[:tok.Col] + fmt.Sprintf("$%d", value) + line[tok.EndCol:]
+				}
+			}
+
+		case AnnotationSymbolic:
+			// Only a NAG directly following a move without annotations becomes a suffix
+			if tok.Kind != TokenNAG || i == 0 {
+				continue
+			}
+			suffix, ok := nagSuffixes[parseNAG(tok.Text)]
+			prev := tokens[i-1]
+			if !ok || prev.Kind != TokenMove || prev.Line != tok.Line {
+				continue
+			}
+			if _, prevSuffix := splitSuffixAnnotation(prev.Text); prevSuffix != "" {
+				continue
+			}
+			lines[tok.Line] = line[:prev.EndCol] + suffix + line[tok.EndCol:]
+		}
+	}
+
+	return lines
+}
@@ -0,0 +1,110 @@
+package pgn
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestValidateNAGs(t *testing.T) {
+	content := `[Event "Test"]
+[Result "*"]
+
+1. e4 $1 e5 $14 2. Nf3 !? Nc6 $146 3. Bb5 $256 {a $999 comment} a6 *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	validator := NewPGNValidator()
+	errors := validator.ValidateFile(tmpFile)
+
+	expected := []string{
+		"Line 4: Warning: Non-standard NAG '$146'",
+		"Line 4: Invalid NAG '$256': value must be between 0 and 255",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, err := range errors {
+		if err.String() != expected[i] {
+			t.Errorf("Message %d:\n  expected: %s\n  got:      %s", i, expected[i], err.String())
+		}
+	}
+}
+
+func TestConvertAnnotations(t *testing.T) {
+	tests := []struct {
+		name     string
+		style    AnnotationStyle
+		input    string
+		expected string
+	}{
+		{
+			name:     "Keep",
+			style:    AnnotationKeep,
+			input:    "1. e4!? $1 e5 2. Nf3 !! Nc6 *",
+			expected: "1. e4!? $1 e5 2. Nf3 !! Nc6 *",
+		},
+		{
+			name:     "Numeric",
+			style:    AnnotationNumeric,
+			input:    "1. e4!? $1 e5?! 2. Nf3 !! Nc6 {good!} *",
+			expected: "1. e4 $5 $1 e5 $6 2. Nf3 $3 Nc6 {good!} *",
+		},
+		{
+			name:     "Symbolic",
+			style:    AnnotationSymbolic,
+			input:    "1. e4 $1 $14 e5 $6 2. Nf3! $2 Nc6 $146 *",
+			expected: "1. e4! $14 e5?! 2. Nf3! $2 Nc6 $146 *",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := NewPGNValidator()
+			validator.SetAnnotationStyle(tt.style)
+			got := validator.convertAnnotations([]string{tt.input})
+			if got[0] != tt.expected {
+				t.Errorf("\n  expected: %s\n  got:      %s", tt.expected, got[0])
+			}
+		})
+	}
+}
+
+func TestFormatAnnotationStyle(t *testing.T) {
+	content := "[Event \"Test\"]\n\n1. e4!? e5 $2 2. Nf3 $14 *\n"
+	tests := []struct {
+		style    AnnotationStyle
+		expected string
+	}{
+		{AnnotationNumeric, "1. e4 $5 e5 $2 2. Nf3 $14 *"},
+		{AnnotationSymbolic, "1. e4!? e5? 2. Nf3 $14 *"},
+	}
+
+	for _, tt := range tests {
+		scanner := NewGameScanner(strings.NewReader(content))
+		if !scanner.Scan() {
+			t.Fatal("Expected a game")
+		}
+		validator := NewPGNValidator()
+		validator.SetAnnotationStyle(tt.style)
+		if got := validator.formatGame(scanner.Game()); !strings.Contains(got, tt.expected) {
+			t.Errorf("Expected movetext %q, got:\n%s", tt.expected, got)
+		}
+	}
+}
+
+func TestParseAnnotationStyle(t *testing.T) {
+	for value, expected := range map[string]AnnotationStyle{
+		"keep":     AnnotationKeep,
+		"numeric":  AnnotationNumeric,
+		"symbolic": AnnotationSymbolic,
+	} {
+		if got, err := ParseAnnotationStyle(value); err != nil || got != expected {
+			t.Errorf("ParseAnnotationStyle(%q) = %v, %v", value, got, err)
+		}
+	}
+	if _, err := ParseAnnotationStyle("glyphs"); err == nil {
+		t.Error("Expected an error for an unknown style")
+	}
+}
@@ -0,0 +1,215 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// playerSpelling is one way a player name is written in a file
+type playerSpelling struct {
+	name string
+	line int // first line where this spelling appears
+}
+
+// collapseSpaces trims a string and collapses runs of whitespace into single spaces
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// LoadAliases reads a player alias file used to normalize White and Black tags.
+// Each non-empty line maps an alias to the canonical name: "Carlsen, M = Carlsen, Magnus".
+// Lines starting with '#' are comments. Aliases are matched ignoring case and extra spaces.
+func (v *PGNValidator) LoadAliases(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("cannot open alias file: %v", err)
+	}
+	defer file.Close()
+
+	aliases := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		alias, canonical, found := strings.Cut(line, "=")
+		alias, canonical = collapseSpaces(alias), collapseSpaces(canonical)
+		if !found || alias == "" || canonical == "" {
+			return fmt.Errorf("line %d: expected 'Alias = Canonical Name', found '%s'", lineNumber, line)
+		}
+		aliases[strings.ToLower(alias)] = canonical
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading alias file: %v", err)
+	}
+
+	v.aliases = aliases
+	return nil
+}
+
+// ShareAliases makes v use the player aliases loaded by another validator, e.g. for validators
+// created per request, without reading the alias file again. The aliases are never modified,
+// so validators sharing them can run concurrently.
+func (v *PGNValidator) ShareAliases(from *PGNValidator) {
+	v.aliases = from.aliases
+}
+
+// normalizePlayerName removes stray whitespace from a player name and applies the alias file
+func (v *PGNValidator) normalizePlayerName(name string) string {
+	normalized := collapseSpaces(name)
+	if canonical, ok := v.aliases[strings.ToLower(normalized)]; ok {
+		return canonical
+	}
+	return normalized
+}
+
+// validatePlayerName checks the format of a White or Black tag value
+func (v *PGNValidator) validatePlayerName(name string, lineNumber int) {
+	normalized := v.normalizePlayerName(name)
+	if normalized != name {
+		v.errors = append(v.errors, ValidationError{
+			Line:     lineNumber,
+			Message:  fmt.Sprintf("Player name auto-corrected: '%s' → '%s'", name, normalized),
+			Rule:     RulePlayerName,
+			Severity: SeverityInfo,
+		})
+	}
+
+	v.recordPlayer(normalized, lineNumber)
+
+	// Unknown players and single names (engines, mononyms) have no format to check
+	if normalized == "" || normalized == "?" || normalized == "-" {
+		return
+	}
+	surname, given, hasComma := strings.Cut(normalized, ",")
+	if !hasComma {
+		if strings.Contains(normalized, " ") {
+			v.errors = append(v.errors, ValidationError{
+				Line:     lineNumber,
+				Message:  fmt.Sprintf("Player name '%s' is not in 'Last, First' format", normalized),
+				Rule:     RulePlayerName,
+				Severity: SeverityInfo,
+			})
+		}
+		return
+	}
+	if strings.TrimSpace(surname) == "" || strings.TrimSpace(given) == "" || strings.HasSuffix(surname, " ") {
+		v.errors = append(v.errors, ValidationError{
+			Line:     lineNumber,
+			Message:  fmt.Sprintf("Player name '%s' is not in 'Last, First' format", normalized),
+			Rule:     RulePlayerName,
+			Severity: SeverityInfo,
+		})
+	}
+}
+
+// recordPlayer remembers how a player name is spelled for the consistency check
+func (v *PGNValidator) recordPlayer(name string, lineNumber int) {
+	key := playerKey(name)
+	if key == "" {
+		return
+	}
+	for _, spelling := range v.players[key] {
+		if spelling.name == name {
+			return
+		}
+	}
+	v.players[key] = append(v.players[key], playerSpelling{name: name, line: lineNumber})
+}
+
+// checkPlayerConsistency reports players whose name is spelled in several ways across the
+// file, such as "Carlsen, M" and "Carlsen, Magnus"
+func (v *PGNValidator) checkPlayerConsistency() {
+	keys := make([]string, 0, len(v.players))
+	for key, spellings := range v.players {
+		if len(spellings) > 1 {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		spellings := v.players[key]
+
+		// Only spellings where one given name abbreviates the other refer to the same player
+		// ("Wang, Hao" and "Wang, Hua" are different people)
+		related := make([]bool, len(spellings))
+		for i := range spellings {
+			for j := i + 1; j < len(spellings); j++ {
+				if sameGivenName(spellings[i].name, spellings[j].name) {
+					related[i], related[j] = true, true
+				}
+			}
+		}
+
+		var listed []string
+		line := 0
+		for i, spelling := range spellings {
+			if !related[i] {
+				continue
+			}
+			listed = append(listed, fmt.Sprintf("'%s' (line %d)", spelling.name, spelling.line))
+			// Report on the line where the second spelling appears
+			if len(listed) == 2 {
+				line = spelling.line
+			}
+		}
+		if len(listed) < 2 {
+			continue
+		}
+
+		v.errors = append(v.errors, ValidationError{
+			Line:     line,
+			Message:  fmt.Sprintf("Warning: Inconsistent spellings of the same player: %s", strings.Join(listed, ", ")),
+			Rule:     RulePlayerSpelling,
+			Severity: SeverityWarning,
+		})
+	}
+}
+
+// sameGivenName reports whether the given names of two spellings can refer to the same
+// person, i.e. one is an abbreviation of the other. Names without a given name only match
+// when they differ in case and punctuation alone ("Player1" and "Player2" do not).
+func sameGivenName(a, b string) bool {
+	givenA, givenB := lettersOnly(givenName(a)), lettersOnly(givenName(b))
+	if givenA == "" || givenB == "" {
+		return alphanumeric(a) == alphanumeric(b)
+	}
+	return strings.HasPrefix(givenA, givenB) || strings.HasPrefix(givenB, givenA)
+}
+
+// alphanumeric returns the lowercase letters and digits of s
+func alphanumeric(s string) string {
+	var sb strings.Builder
+	for _, char := range s {
+		if unicode.IsLetter(char) || unicode.IsDigit(char) {
+			sb.WriteRune(unicode.ToLower(char))
+		}
+	}
+	return sb.String()
+}
+
+// givenName returns the part of a player name after the surname
+func givenName(name string) string {
+	if _, given, found := strings.Cut(name, ","); found {
+		return given
+	}
+	if fields := strings.Fields(name); len(fields) > 1 {
+		return strings.Join(fields[:len(fields)-1], " ")
+	}
+	return ""
+}
@@ -0,0 +1,126 @@
+package pgn
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestPlayerNameChecks(t *testing.T) {
+	content := `[Event "Test"]
+[White "Carlsen, Magnus"]
+[Black "Fabiano Caruana"]
+[Result "1-0"]
+
+1. e4 e5 1-0
+
+[Event "Test"]
+[White " Nepomniachtchi,  Ian"]
+[Black "Carlsen, M"]
+[Result "0-1"]
+
+1. d4 d5 0-1
+
+[Event "Test"]
+[White "Wang, Hao"]
+[Black "Wang, Hua"]
+[Result "*"]
+
+1. c4 *
+
+[Event "Test"]
+[White "Stockfish"]
+[Black "?"]
+[Result "*"]
+
+1. Nf3 *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	validator := NewPGNValidator()
+	errors := validator.ValidateFile(tmpFile)
+
+	expected := []string{
+		"Line 3: Player name 'Fabiano Caruana' is not in 'Last, First' format",
+		"Line 9: Player name auto-corrected: ' Nepomniachtchi,  Ian' → 'Nepomniachtchi, Ian'",
+		"Line 10: Warning: Inconsistent spellings of the same player: 'Carlsen, Magnus' (line 2), 'Carlsen, M' (line 10)",
+	}
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d messages, got %d: %v", len(expected), len(errors), errors)
+	}
+	for i, err := range errors {
+		if err.String() != expected[i] {
+			t.Errorf("Message %d:\n  expected: %s\n  got:      %s", i, expected[i], err.String())
+		}
+	}
+}
+
+func TestPlayerAliases(t *testing.T) {
+	dir := t.TempDir()
+	aliasFile := filepath.Join(dir, "aliases.txt")
+	aliases := "# Canonical spellings\nCarlsen, M = Carlsen, Magnus\nmagnus carlsen=Carlsen, Magnus\n"
+	if err := os.WriteFile(aliasFile, []byte(aliases), 0644); err != nil {
+		t.Fatalf("Failed to write alias file: %v", err)
+	}
+
+	content := `[Event "Test"]
+[White "Magnus  Carlsen"]
+[Black "Carlsen, M"]
+[Result "*"]
+
+1. e4 *
+`
+	inputFile := filepath.Join(dir, "input.pgn")
+	if err := os.WriteFile(inputFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+
+	validator := NewPGNValidator()
+	if err := validator.LoadAliases(aliasFile); err != nil {
+		t.Fatalf("LoadAliases failed: %v", err)
+	}
+
+	errors := validator.ValidateFile(inputFile)
+	for _, err := range errors {
+		if !strings.Contains(err.Message, "auto-corrected") {
+			t.Errorf("Unexpected message: %s", err)
+		}
+	}
+	if len(errors) != 2 {
+		t.Errorf("Expected 2 auto-corrections, got %d: %v", len(errors), errors)
+	}
+
+	// A validator sharing the aliases corrects the same names
+	shared := NewPGNValidator()
+	shared.ShareAliases(validator)
+	if sharedErrors := shared.ValidateFile(inputFile); !reflect.DeepEqual(sharedErrors, errors) {
+		t.Errorf("Expected %v with the shared aliases, got %v", errors, sharedErrors)
+	}
+
+	outputFile := filepath.Join(dir, "output.pgn")
+	if err := validator.WriteCorrectedFile(inputFile, outputFile); err != nil {
+		t.Fatalf("WriteCorrectedFile failed: %v", err)
+	}
+	output, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+	if strings.Count(string(output), `"Carlsen, Magnus"`) != 2 {
+		t.Errorf("Expected both names normalized, got:\n%s", output)
+	}
+}
+
+func TestLoadAliasesMalformed(t *testing.T) {
+	aliasFile := filepath.Join(t.TempDir(), "aliases.txt")
+	if err := os.WriteFile(aliasFile, []byte("Carlsen, M\n"), 0644); err != nil {
+		t.Fatalf("Failed to write alias file: %v", err)
+	}
+
+	validator := NewPGNValidator()
+	if err := validator.LoadAliases(aliasFile); err == nil {
+		t.Error("Expected an error for a line without '='")
+	}
+}
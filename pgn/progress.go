@@ -0,0 +1,63 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"io"
+	"os"
+)
+
+// ProgressReporter receives the progress of validations and corrections, e.g. to draw a
+// progress bar. Progress is measured in bytes of input read.
+type ProgressReporter interface {
+	Start(task string, total int64) // a task such as "Validating" begins; total is 0 if unknown
+	Update(done int64)              // done bytes of the input have been read
+	Clear()                         // other output is about to be written, e.g. an error
+	Finish(completed bool)          // the task ends; completed is false if it stopped early
+}
+
+// SetProgress sets the reporter receiving the progress of validations and corrections, nil
+// for none (the default)
+func (v *PGNValidator) SetProgress(progress ProgressReporter) {
+	v.progress = progress
+}
+
+// progressReader reports the bytes read through it
+type progressReader struct {
+	r        io.Reader
+	done     int64
+	progress ProgressReporter
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.done += int64(n)
+		r.progress.Update(r.done)
+	}
+	return n, err
+}
+
+// inputSize returns the size of the regular file read by r, 0 if r is not a file
+func inputSize(r io.Reader) int64 {
+	if file, ok := r.(interface{ Stat() (os.FileInfo, error) }); ok {
+		if info, err := file.Stat(); err == nil && info.Mode().IsRegular() {
+			return info.Size()
+		}
+	}
+	return 0
+}
+
+// startProgress starts reporting the progress of a task reading r. It returns the reader to
+// read from instead and the function ending the task.
+func (v *PGNValidator) startProgress(task string, r io.Reader) (io.Reader, func(completed bool)) {
+	if v.progress == nil {
+		return r, func(bool) {}
+	}
+	v.progress.Start(task, inputSize(r))
+	return &progressReader{r: r, progress: v.progress}, v.progress.Finish
+}
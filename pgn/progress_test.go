@@ -0,0 +1,60 @@
+package pgn
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// recordingProgress records the calls of a ProgressReporter
+type recordingProgress struct {
+	calls []string
+	done  int64
+}
+
+func (p *recordingProgress) Start(task string, total int64) {
+	p.calls = append(p.calls, fmt.Sprintf("start %s %d", task, total))
+}
+func (p *recordingProgress) Update(done int64) { p.done = done }
+func (p *recordingProgress) Clear()            { p.calls = append(p.calls, "clear") }
+func (p *recordingProgress) Finish(completed bool) {
+	p.calls = append(p.calls, fmt.Sprintf("finish %d %v", p.done, completed))
+}
+
+func TestProgressReporter(t *testing.T) {
+	content := "[Event \"Test\"]\r\n[Date \"2024-01-15\"]\r\n\r\n1. e4 e5 *\r\n"
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	// Files report their exact size, CRLF line endings included
+	progress := &recordingProgress{}
+	validator := NewPGNValidator()
+	validator.SetProgress(progress)
+	validator.ValidateFile(tmpFile)
+	size := len(content)
+	if expected := fmt.Sprintf("start Validating %d,finish %d true", size, size); strings.Join(progress.calls, ",") != expected {
+		t.Errorf("Expected %q, got %q", expected, strings.Join(progress.calls, ","))
+	}
+
+	// Readers of unknown size, with errors written as they are found
+	progress.calls = nil
+	_, err := validator.ValidateReader(context.Background(), strings.NewReader(content), ValidateOptions{
+		OnError:   func(ValidationError) {},
+		MaxErrors: 1,
+	})
+	if expected := fmt.Sprintf("start Validating 0,clear,finish %d false", size); err != ErrTooManyErrors || strings.Join(progress.calls, ",") != expected {
+		t.Errorf("Expected %q, got %q (%v)", expected, strings.Join(progress.calls, ","), err)
+	}
+
+	progress.calls = nil
+	outputFile := tmpFile + ".out"
+	defer os.Remove(outputFile)
+	if err := validator.WriteCorrectedFile(tmpFile, outputFile); err != nil {
+		t.Fatalf("WriteCorrectedFile failed: %v", err)
+	}
+	if expected := fmt.Sprintf("start Correcting %d,finish %d true", size, size); strings.Join(progress.calls, ",") != expected {
+		t.Errorf("Expected %q, got %q", expected, strings.Join(progress.calls, ","))
+	}
+}
@@ -0,0 +1,243 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// repairLine tracks one line of play while looking for unclosed variations
+type repairLine struct {
+	open       int       // index of the "(" token opening the variation, -1 for the mainline
+	pos, prev  *Position // current and previous position, nil once replay has failed
+	replayable bool      // the line starts from a known position
+	moves      int       // legal moves replayed on this line
+	illegal    int       // index of the first move that does not fit the line, -1 if none
+}
+
+// repairMovetext fixes unbalanced comments and variations in the movetext of a game.
+// Parentheses inside comments are text and are left alone. An unclosed comment is closed
+// before the first move it swallowed, an unclosed variation before the first move that
+// does not fit it, and stray closing delimiters are removed.
+func (v *PGNValidator) repairMovetext(g *Game) []string {
+	lines := slices.Clone(g.Movetext())
+
+	// Close comments first, one at a time, since each fix changes how the rest is tokenized
+	for range len(lines) + 1 {
+		if !v.closeComment(lines) {
+			break
+		}
+	}
+
+	// Remove stray closing delimiters
+	tokens := tokenizeMovetext(lines, 0)
+	depth := 0
+	var stray []Token
+	for _, tok := range tokens {
+		switch tok.Kind {
+		case TokenVariationStart:
+			depth++
+		case TokenVariationEnd:
+			if depth == 0 {
+				stray = append(stray, tok)
+			} else {
+				depth--
+			}
+		case TokenUnknown:
+			if tok.Text == "}" {
+				stray = append(stray, tok)
+			}
+		}
+	}
+	// Delete from the end so earlier positions stay valid
+	sort.Slice(stray, func(i, j int) bool {
+		return stray[i].Line > stray[j].Line || stray[i].Line == stray[j].Line && stray[i].Col > stray[j].Col
+	})
+	for _, tok := range stray {
+		line := lines[tok.Line]
+		lines[tok.Line] = line[:tok.Col] + line[tok.EndCol:]
+	}
+
+	// Close variations left open, innermost first
+	if depth > 0 {
+		start := gameStartPosition(g)
+		for range depth {
+			if !closeVariation(lines, start) {
+				break
+			}
+		}
+	}
+
+	return lines
+}
+
+// closeComment closes the first comment that swallowed part of the movetext. A comment is
+// considered unclosed if it runs to the end of the movetext or contains an opening brace.
+// It reports whether a comment was closed.
+func (v *PGNValidator) closeComment(lines []string) bool {
+	for _, tok := range tokenizeMovetext(lines, 0) {
+		if tok.Kind != TokenComment {
+			continue
+		}
+
+		// The comment body ends at a nested '{' or at the end of the movetext
+		endLine, endCol := tok.EndLine, tok.EndCol
+		nested := false
+		if !tok.Unterminated {
+			i := strings.IndexByte(tok.Text, '{')
+			if i < 0 {
+				continue
+			}
+			nested = true
+			endLine, endCol = tok.Line, tok.Col+1
+			for _, char := range []byte(tok.Text[:i]) {
+				if char == '\n' {
+					endLine++
+					endCol = 0
+				} else {
+					endCol++
+				}
+			}
+		} else {
+			// Leave trailing blank lines out of the comment
+			for endLine > tok.Line && strings.TrimSpace(lines[endLine]) == "" {
+				endLine--
+				endCol = len(lines[endLine])
+			}
+		}
+
+		// Tokenize the comment body as movetext, keeping line and column positions
+		body := make([]string, endLine-tok.Line+1)
+		for i := range body {
+			line := lines[tok.Line+i]
+			if i == len(body)-1 {
+				line = line[:endCol]
+			}
+			if i == 0 {
+				line = strings.Repeat(" ", tok.Col+1) + line[tok.Col+1:]
+			}
+			body[i] = line
+		}
+		bodyTokens := tokenizeMovetext(body, tok.Line)
+
+		// Close before the first move number followed by a valid move, or before a final result
+		closeLine, closeCol := endLine, endCol
+		for i, bodyTok := range bodyTokens {
+			if bodyTok.Kind == TokenMoveNumber && i+1 < len(bodyTokens) && bodyTokens[i+1].Kind == TokenMove {
+				if core, _ := splitSuffixAnnotation(bodyTokens[i+1].Text); v.isValidMoveNotation(core) {
+					closeLine, closeCol = bodyTok.Line, bodyTok.Col
+					break
+				}
+			}
+			if bodyTok.Kind == TokenResult && i == len(bodyTokens)-1 && !nested {
+				closeLine, closeCol = bodyTok.Line, bodyTok.Col
+				break
+			}
+		}
+
+		closeLine, closeCol = backOverSpaces(lines, closeLine, closeCol, tok.Line)
+		lines[closeLine] = lines[closeLine][:closeCol] + "}" + lines[closeLine][closeCol:]
+		return true
+	}
+	return false
+}
+
+// closeVariation closes the innermost variation left open, replaying the movetext to find
+// the first move that does not belong to it. It reports whether a variation was closed.
+func closeVariation(lines []string, start *Position) bool {
+	tokens := tokenizeMovetext(lines, 0)
+
+	cur := &repairLine{open: -1, pos: start, replayable: start != nil, illegal: -1}
+	stack := []*repairLine{}
+
+	for i, tok := range tokens {
+		switch tok.Kind {
+		case TokenMove:
+			cur.prev = cur.pos
+			if cur.pos == nil {
+				continue
+			}
+			m, err := cur.pos.ParseSAN(tok.Text)
+			if err != nil {
+				if cur.illegal < 0 {
+					cur.illegal = i
+				}
+				cur.pos = nil
+				continue
+			}
+			cur.pos = cur.pos.Play(m)
+			cur.moves++
+
+		case TokenVariationStart:
+			stack = append(stack, cur)
+			cur = &repairLine{open: i, pos: cur.prev, replayable: cur.prev != nil, illegal: -1}
+
+		case TokenVariationEnd:
+			if len(stack) > 0 {
+				cur = stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	if len(stack) == 0 {
+		return false
+	}
+
+	// cur is now the innermost unclosed variation
+	openTok := tokens[cur.open]
+	var closeLine, closeCol int
+
+	last := tokens[len(tokens)-1]
+
+	switch {
+	case cur.replayable && cur.illegal >= 0 && cur.moves > 0:
+		// Close before the first move that does not fit, and its move number
+		at := cur.illegal
+		if at > 0 && tokens[at-1].Kind == TokenMoveNumber {
+			at--
+		}
+		closeLine, closeCol = tokens[at].Line, tokens[at].Col
+
+	case cur.replayable && cur.illegal < 0 && last.Kind == TokenResult && len(tokens)-1 > cur.open:
+		// All moves fit: close before the game termination marker
+		closeLine, closeCol = last.Line, last.Col
+
+	case cur.replayable && cur.illegal < 0:
+		closeLine, closeCol = len(lines)-1, len(lines[len(lines)-1])
+
+	default:
+		// The variation cannot be replayed: close it at the end of its first line,
+		// before a rest-of-line comment
+		closeLine, closeCol = openTok.Line, len(lines[openTok.Line])
+		for _, tok := range tokens[cur.open:] {
+			if tok.Line == openTok.Line && tok.Kind == TokenLineComment {
+				closeCol = tok.Col
+			}
+		}
+	}
+
+	closeLine, closeCol = backOverSpaces(lines, closeLine, closeCol, openTok.Line)
+	lines[closeLine] = lines[closeLine][:closeCol] + ")" + lines[closeLine][closeCol:]
+	return true
+}
+
+// backOverSpaces moves an insertion point back over whitespace, to the end of the previous
+// line if needed, so that inserted closers stick to the text they close. It does not move
+// before minLine.
+func backOverSpaces(lines []string, line, col, minLine int) (int, int) {
+	for {
+		trimmed := strings.TrimRight(lines[line][:col], " \t\r")
+		if trimmed != "" || line <= minLine {
+			return line, len(trimmed)
+		}
+		line--
+		col = len(lines[line])
+	}
+}
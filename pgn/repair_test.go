@@ -0,0 +1,33 @@
+package pgn
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepairMovetext(t *testing.T) {
+	tests := []struct {
+		name     string
+		movetext string
+		expected string
+	}{
+		{"parentheses in comment", "1. e4 {a comment (with a paren} e5 *", "1. e4 {a comment (with a paren} e5 *"},
+		{"unclosed comment", "1. e4 {unclosed 2. Nf3 Nc6 *", "1. e4 {unclosed} 2. Nf3 Nc6 *"},
+		{"unclosed comment before result", "1. e4 e5 {good game 1-0", "1. e4 e5 {good game} 1-0"},
+		{"comment swallowing a comment", "1. e4 {first 2. d4 {second} d5}", "1. e4 {first} 2. d4 {second} d5"},
+		{"unclosed variation", "1. e4 e5 (1... c5 2. Nf3 2. Nf3 Nc6 *", "1. e4 e5 (1... c5 2. Nf3) 2. Nf3 Nc6 *"},
+		{"unclosed variation until result", "1. d4 (1. e4 e5 *", "1. d4 (1. e4 e5) *"},
+		{"stray closing parenthesis", "1. e4 e5) 2. Nf3 *", "1. e4 e5 2. Nf3 *"},
+		{"multi-line comment", "1. e4 {a comment\non two lines} e5 *", "1. e4 {a comment\non two lines} e5 *"},
+		{"comment closed on previous line", "1. e4 {unclosed\n2. Nf3 *", "1. e4 {unclosed}\n2. Nf3 *"},
+	}
+
+	validator := NewPGNValidator()
+	for _, tt := range tests {
+		game := &Game{StartLine: 1, Lines: strings.Split(tt.movetext, "\n")}
+		repaired := strings.Join(validator.repairMovetext(game), "\n")
+		if repaired != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, repaired)
+		}
+	}
+}
@@ -0,0 +1,300 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// zobristKeys holds the random numbers hashed into a position key
+type zobristKeys struct {
+	pieces    [16][64]uint64 // indexed by Piece and Square
+	castling  [16]uint64     // indexed by castling rights
+	enPassant [8]uint64      // indexed by the file of the en passant square
+	black     uint64         // Black to move
+}
+
+// zobrist holds fixed keys, so hashes are stable across runs
+var zobrist = newZobristKeys(0x9e3779b97f4a7c15)
+
+// newZobristKeys generates keys with the splitmix64 generator
+func newZobristKeys(seed uint64) *zobristKeys {
+	next := func() uint64 {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		return z ^ (z >> 31)
+	}
+
+	keys := &zobristKeys{}
+	for piece := range keys.pieces {
+		for sq := range keys.pieces[piece] {
+			keys.pieces[piece][sq] = next()
+		}
+	}
+	for i := range keys.castling {
+		keys.castling[i] = next()
+	}
+	for i := range keys.enPassant {
+		keys.enPassant[i] = next()
+	}
+	keys.black = next()
+	return keys
+}
+
+// Hash returns the Zobrist hash of the position: pieces, side to move, castling rights and
+// en passant square, ignoring the move counters. The en passant square only counts when a
+// pawn can capture on it, so positions reached by different move orders hash the same.
+func (p *Position) Hash() uint64 {
+	var h uint64
+	for sq, piece := range p.board {
+		if piece != NoPiece {
+			h ^= zobrist.pieces[piece][sq]
+		}
+	}
+	h ^= zobrist.castling[p.castling]
+	if p.Turn == Black {
+		h ^= zobrist.black
+	}
+	if p.epSquare != NoSquare {
+		// The capturing pawn stands next to the target square, on the rank behind it
+		rank := p.epSquare.Rank() - 1
+		if p.Turn == Black {
+			rank = p.epSquare.Rank() + 1
+		}
+		for _, df := range [2]int{-1, 1} {
+			if sq, ok := offsetSquare(newSquare(p.epSquare.File(), rank), df, 0); ok && p.board[sq] == NewPiece(p.Turn, Pawn) {
+				h ^= zobrist.enPassant[p.epSquare.File()]
+				break
+			}
+		}
+	}
+	return h
+}
+
+// materialOrder is the order of pieces in a material signature
+var materialOrder = []PieceType{King, Queen, Rook, Bishop, Knight, Pawn}
+
+// material counts the pieces of each side, indexed by Color and PieceType
+type material [2][7]int
+
+// Material returns the material signature of the position, e.g. "KRPvKR"
+func (p *Position) Material() string {
+	return p.material().String()
+}
+
+func (p *Position) material() material {
+	var m material
+	for _, piece := range p.board {
+		if piece != NoPiece {
+			m[piece.Color()][piece.Type()]++
+		}
+	}
+	return m
+}
+
+// String returns the signature of the material: White's pieces, "v", then Black's
+func (m material) String() string {
+	var sb strings.Builder
+	for c, side := range m {
+		if c == int(Black) {
+			sb.WriteByte('v')
+		}
+		for _, t := range materialOrder {
+			letter := pieceLetters[t]
+			if t == Pawn {
+				letter = "P"
+			}
+			sb.WriteString(strings.Repeat(letter, side[t]))
+		}
+	}
+	return sb.String()
+}
+
+// parseMaterial parses a material signature such as "KRPvKR" (pieces in any order)
+func parseMaterial(signature string) (material, error) {
+	var m material
+	sides := strings.Split(strings.TrimSpace(signature), "v")
+	if len(sides) != 2 {
+		return m, fmt.Errorf("expected White's and Black's pieces separated by 'v', e.g. KRPvKR")
+	}
+	for c, side := range sides {
+		for _, letter := range side {
+			t := NoPieceType
+			for _, candidate := range materialOrder {
+				if string(letter) == pieceLetters[candidate] || candidate == Pawn && letter == 'P' {
+					t = candidate
+				}
+			}
+			if t == NoPieceType {
+				return m, fmt.Errorf("unknown piece '%c' (expected K, Q, R, B, N or P)", letter)
+			}
+			m[c][t]++
+		}
+		if m[c][King] != 1 {
+			return m, fmt.Errorf("each side needs exactly one king")
+		}
+	}
+	return m, nil
+}
+
+// PositionSearch finds games reaching a position or a material configuration
+type PositionSearch struct {
+	hash       uint64
+	byHash     bool
+	material   material
+	byMaterial bool
+	variations bool // also search the variations
+}
+
+// NewPositionSearch creates a search for the position of a FEN (move counters are ignored)
+// and for a material signature such as "KRPvKR", matched with either side having the first
+// part. Empty arguments are not searched; a position must match both of the others.
+func NewPositionSearch(fen, signature string, variations bool) (*PositionSearch, error) {
+	s := &PositionSearch{variations: variations}
+	if fen != "" {
+		pos, err := ParseFEN(fen)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FEN: %v", err)
+		}
+		s.hash, s.byHash = pos.Hash(), true
+	}
+	if signature != "" {
+		m, err := parseMaterial(signature)
+		if err != nil {
+			return nil, fmt.Errorf("invalid material signature '%s': %v", signature, err)
+		}
+		s.material, s.byMaterial = m, true
+	}
+	if !s.byHash && !s.byMaterial {
+		return nil, fmt.Errorf("nothing to search: expected a FEN or a material signature")
+	}
+	return s, nil
+}
+
+// matches reports whether a position satisfies the search
+func (s *PositionSearch) matches(p *Position) bool {
+	if s.byHash && p.Hash() != s.hash {
+		return false
+	}
+	if s.byMaterial {
+		m := p.material()
+		if m != s.material && m != (material{s.material[Black], s.material[White]}) {
+			return false
+		}
+	}
+	return true
+}
+
+// PositionMatch is a game reaching the searched position
+type PositionMatch struct {
+	File   string
+	Game   int    // index of the game in the file, from 1
+	Ply    int    // plies played from the start of the game to reach the position
+	Line   int    // line number of the move reaching the position
+	Move   string // move reaching the position, e.g. "12. Nf3" or "12... Nf6", "" for the initial position
+	White  string
+	Black  string
+	Result string
+}
+
+// String describes the match, e.g. "twic.pgn:123: game 4, ply 23 (12. Nf3), Carlsen - Caruana 1-0"
+func (m PositionMatch) String() string {
+	move := "initial position"
+	if m.Move != "" {
+		move = m.Move
+	}
+	return fmt.Sprintf("%s:%d: game %d, ply %d (%s), %s - %s %s",
+		m.File, m.Line, m.Game, m.Ply, move, m.White, m.Black, m.Result)
+}
+
+// SearchGame returns the first position of a game satisfying the search: its ply, the line
+// and the move reaching it. The mainline is searched first, then the variations if enabled.
+func (s *PositionSearch) SearchGame(g *Game) (ply, line int, move string, found bool) {
+	tree := g.MoveTree()
+	if tree.Start == nil {
+		return 0, 0, "", false
+	}
+	startPly := tree.Start.Ply()
+	if s.matches(tree.Start) {
+		return 0, firstLine(g), "", true
+	}
+
+	var search func(moves []*MoveNode) (*MoveNode, bool)
+	search = func(moves []*MoveNode) (*MoveNode, bool) {
+		for _, node := range moves {
+			if node.Position == nil {
+				break
+			}
+			if s.matches(node.Position) {
+				return node, true
+			}
+		}
+		if !s.variations {
+			return nil, false
+		}
+		for _, node := range moves {
+			for _, variation := range node.Variations {
+				if found, ok := search(variation); ok {
+					return found, true
+				}
+			}
+		}
+		return nil, false
+	}
+
+	node, ok := search(tree.Moves)
+	if !ok {
+		return 0, 0, "", false
+	}
+	if node.Color() == White {
+		move = fmt.Sprintf("%d. %s", node.MoveNumber(), node.SAN)
+	} else {
+		move = fmt.Sprintf("%d... %s", node.MoveNumber(), node.SAN)
+	}
+	return node.Ply + 1 - startPly, node.Line, move, true
+}
+
+// SearchFile searches every game of a PGN file, calling visit for each game that matches.
+// It returns the number of games read.
+func (s *PositionSearch) SearchFile(filename string, visit func(PositionMatch)) (int, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return 0, fmt.Errorf("cannot open input file: %v", err)
+	}
+	defer file.Close()
+
+	games := 0
+	scanner := NewGameScanner(file)
+	for scanner.Scan() {
+		game := scanner.Game()
+		if !hasContent(game) {
+			continue
+		}
+		games++
+		if ply, line, move, found := s.SearchGame(game); found {
+			visit(PositionMatch{
+				File:   filename,
+				Game:   games,
+				Ply:    ply,
+				Line:   line,
+				Move:   move,
+				White:  game.Tag("White"),
+				Black:  game.Tag("Black"),
+				Result: game.Tag("Result"),
+			})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return games, fmt.Errorf("error reading: %v", err)
+	}
+	return games, nil
+}
@@ -0,0 +1,122 @@
+package pgn
+
+import (
+	"os"
+	"testing"
+)
+
+func TestPositionHash(t *testing.T) {
+	play := func(moves ...string) *Position {
+		pos := NewStartPosition()
+		for _, san := range moves {
+			m, err := pos.ParseSAN(san)
+			if err != nil {
+				t.Fatalf("%s: %v", san, err)
+			}
+			pos = pos.Play(m)
+		}
+		return pos
+	}
+
+	// Transpositions reach the same hash, move counters are ignored
+	a := play("d4", "Nf6", "c4", "e6", "Nc3", "d5")
+	b := play("c4", "e6", "Nc3", "d5", "d4", "Nf6")
+	if a.Hash() != b.Hash() {
+		t.Errorf("Expected transposed positions to have the same hash")
+	}
+	fen, err := ParseFEN("rnbqkb1r/ppp2ppp/4pn2/3p4/2PP4/2N5/PP2PPPP/R1BQKBNR w KQkq - 4 40")
+	if err != nil || fen.Hash() != a.Hash() {
+		t.Errorf("Expected the FEN position to have the same hash (error %v)", err)
+	}
+
+	// An en passant square counts only when a pawn can capture on it
+	noCapture, _ := ParseFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	if noCapture.Hash() != play("e4").Hash() {
+		t.Errorf("Expected an unusable en passant square to be ignored")
+	}
+	withCapture, _ := ParseFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	withoutCapture, _ := ParseFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	if withCapture.Hash() == withoutCapture.Hash() {
+		t.Errorf("Expected a usable en passant square to change the hash")
+	}
+	if a.Hash() == play("d4", "Nf6", "c4", "e6", "Nc3").Hash() {
+		t.Errorf("Expected different positions to have different hashes")
+	}
+}
+
+func TestParseMaterial(t *testing.T) {
+	m, err := parseMaterial("KPRvRK")
+	if err != nil || m.String() != "KRPvKR" {
+		t.Errorf("Expected KRPvKR, got %s (error %v)", m, err)
+	}
+	if NewStartPosition().Material() != "KQRRBBNNPPPPPPPPvKQRRBBNNPPPPPPPP" {
+		t.Errorf("Unexpected material of the starting position: %s", NewStartPosition().Material())
+	}
+
+	for _, signature := range []string{"KRP", "KRPvKRvK", "KXvK", "RvK", "KKvK"} {
+		if _, err := parseMaterial(signature); err == nil {
+			t.Errorf("Expected an error for %q", signature)
+		}
+	}
+}
+
+func TestSearchFile(t *testing.T) {
+	content := `[Event "Open"]
+[White "A"]
+[Black "B"]
+[Result "*"]
+
+1. e4 e6 2. d4 d5 *
+
+[Event "Open"]
+[White "C"]
+[Black "D"]
+[Result "*"]
+
+1. d4 e6 2. e4 (2. c4 d5)
+2... d5 *
+
+[Event "Open"]
+[White "E"]
+[Black "F"]
+[Result "*"]
+
+1. d4 d5 2. c4 e6 (2... c6) *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	// French defence, reached by transposition in the second game
+	search, err := NewPositionSearch("rnbqkbnr/ppp2ppp/4p3/3p4/3PP3/8/PPP2PPP/RNBQKBNR w KQkq - 0 3", "", false)
+	if err != nil {
+		t.Fatalf("NewPositionSearch failed: %v", err)
+	}
+	var matches []PositionMatch
+	games, err := search.SearchFile(tmpFile, func(m PositionMatch) { matches = append(matches, m) })
+	if err != nil {
+		t.Fatalf("SearchFile failed: %v", err)
+	}
+	if games != 3 || len(matches) != 2 {
+		t.Fatalf("Expected 2 of 3 games, got %d of %d: %v", len(matches), games, matches)
+	}
+	if m := matches[1]; m.Game != 2 || m.Ply != 4 || m.Line != 14 || m.Move != "2... d5" || m.White != "C" {
+		t.Errorf("Unexpected match %s", m)
+	}
+
+	// Slav defence, only in a variation of the third game
+	for _, variations := range []bool{false, true} {
+		search, err := NewPositionSearch("rnbqkbnr/pp2pppp/2p5/3p4/2PP4/8/PP2PPPP/RNBQKBNR w KQkq - 0 3", "", variations)
+		if err != nil {
+			t.Fatalf("NewPositionSearch failed: %v", err)
+		}
+		matches = nil
+		search.SearchFile(tmpFile, func(m PositionMatch) { matches = append(matches, m) })
+		if variations != (len(matches) == 1) {
+			t.Errorf("With variations=%v, got matches %v", variations, matches)
+		}
+	}
+
+	if _, err := NewPositionSearch("", "", false); err == nil {
+		t.Errorf("Expected an error without FEN and material")
+	}
+}
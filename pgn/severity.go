@@ -0,0 +1,83 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is how serious a validation error is. The zero value is SeverityError, so that
+// errors built without a severity are never taken lightly.
+type Severity int
+
+const (
+	SeverityError   Severity = iota // invalid PGN
+	SeverityWarning                 // a doubtful value that may be right, e.g. an implausible round
+	SeverityInfo                    // a problem corrected automatically, e.g. a date in another format
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	default:
+		return "error"
+	}
+}
+
+// AtLeast reports whether s is as serious as threshold or more
+func (s Severity) AtLeast(threshold Severity) bool {
+	return s <= threshold
+}
+
+// MarshalText writes the severity by name, e.g. in JSON
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText reads a severity written by MarshalText
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, err := ParseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+	*s = severity
+	return nil
+}
+
+// DescribeCounts describes numbers of messages by severity, e.g. "2 errors, 1 warning, 3 info"
+func DescribeCounts(counts map[Severity]int) string {
+	var parts []string
+	for s := SeverityError; s <= SeverityInfo; s++ {
+		if counts[s] == 0 {
+			continue
+		}
+		name := s.String()
+		if counts[s] != 1 && s != SeverityInfo {
+			name += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", counts[s], name))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseSeverity returns the severity named error, warning or info
+func ParseSeverity(name string) (Severity, error) {
+	switch strings.ToLower(name) {
+	case "error":
+		return SeverityError, nil
+	case "warning":
+		return SeverityWarning, nil
+	case "info":
+		return SeverityInfo, nil
+	default:
+		return SeverityError, fmt.Errorf("unknown severity '%s' (expected error, warning or info)", name)
+	}
+}
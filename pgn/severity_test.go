@@ -0,0 +1,75 @@
+package pgn
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSeverity(t *testing.T) {
+	content := `[Event "Test"]
+[Date "2024-01-15"]
+[White "Magnus Carlsen"]
+[Black "Nakamura,  Hikaru"]
+[Result "1-1"]
+
+1. e4 e5 *
+`
+	expected := map[int]Severity{
+		2: SeverityInfo,  // Date auto-corrected
+		3: SeverityInfo,  // not in 'Last, First' format
+		4: SeverityInfo,  // Player name auto-corrected
+		5: SeverityError, // Invalid result
+	}
+	errors := NewPGNValidator().ValidateString(content)
+	if len(errors) != len(expected) {
+		t.Fatalf("Expected %d errors, got %v", len(expected), errors)
+	}
+	for _, e := range errors {
+		if e.Severity != expected[e.Line] {
+			t.Errorf("Expected severity %v for '%s', got %v", expected[e.Line], e.Message, e.Severity)
+		}
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for name, expected := range map[string]Severity{"error": SeverityError, "Warning": SeverityWarning, "info": SeverityInfo} {
+		if severity, err := ParseSeverity(name); err != nil || severity != expected {
+			t.Errorf("ParseSeverity(%q) = %v, %v; expected %v", name, severity, err, expected)
+		}
+	}
+	if _, err := ParseSeverity("never"); err == nil {
+		t.Error("Expected an error for an unknown severity")
+	}
+	if !SeverityError.AtLeast(SeverityWarning) || !SeverityWarning.AtLeast(SeverityWarning) || SeverityInfo.AtLeast(SeverityWarning) {
+		t.Error("Expected errors and warnings to be at least warnings, and info not")
+	}
+}
+
+func TestSeverityJSON(t *testing.T) {
+	e := ValidationError{Line: 3, Message: "Warning: test", Rule: RuleRound, Severity: SeverityWarning}
+	data, err := json.Marshal(e)
+	if err != nil || string(data) != `{"line":3,"message":"Warning: test","rule":"round","severity":"warning"}` {
+		t.Fatalf("Unexpected JSON %s (%v)", data, err)
+	}
+	var decoded ValidationError
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != e {
+		t.Errorf("Expected %+v, got %+v (%v)", e, decoded, err)
+	}
+}
+
+func TestDescribeCounts(t *testing.T) {
+	tests := []struct {
+		counts   map[Severity]int
+		expected string
+	}{
+		{map[Severity]int{SeverityError: 1, SeverityWarning: 1, SeverityInfo: 1}, "1 error, 1 warning, 1 info"},
+		{map[Severity]int{SeverityError: 2, SeverityInfo: 17}, "2 errors, 17 info"},
+		{map[Severity]int{SeverityWarning: 3}, "3 warnings"},
+		{map[Severity]int{}, ""},
+	}
+	for _, tt := range tests {
+		if got := DescribeCounts(tt.counts); got != tt.expected {
+			t.Errorf("DescribeCounts(%v) = %q, expected %q", tt.counts, got, tt.expected)
+		}
+	}
+}
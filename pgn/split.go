@@ -0,0 +1,243 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxOpenSplitFiles limits the output files kept open while splitting; the others are
+// reopened in append mode when needed
+const maxOpenSplitFiles = 128
+
+// monthPattern matches the year and month of a date tag value
+// Groups: (1) year, (2) month
+var monthPattern = regexp.MustCompile(`^(\d{4})[.\-/](\d{2})`)
+
+// SplitMode selects how games are grouped into output files
+type SplitMode int
+
+const (
+	SplitByEvent  SplitMode = iota // one file per Event tag
+	SplitByPlayer                  // one file per player, holding their games as White and Black
+	SplitByMonth                   // one file per month of the Date tag, e.g. 2023-05.pgn
+	SplitByCount                   // files of a fixed number of games
+)
+
+// ParseSplitMode converts a command-line value (event, player, month, count) to a SplitMode
+func ParseSplitMode(value string) (SplitMode, error) {
+	switch value {
+	case "event":
+		return SplitByEvent, nil
+	case "player":
+		return SplitByPlayer, nil
+	case "month":
+		return SplitByMonth, nil
+	case "count":
+		return SplitByCount, nil
+	}
+	return SplitByEvent, fmt.Errorf("unknown split mode '%s' (expected event, player, month or count)", value)
+}
+
+// splitOutput is an output file of a split
+type splitOutput struct {
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// Splitter writes the games of PGN files to several files, grouped by a SplitMode
+type Splitter struct {
+	validator *PGNValidator
+	mode      SplitMode
+	count     int    // games per file for SplitByCount
+	dir       string // output directory
+	canonical bool   // write games in PGN export format
+
+	open   map[string]*splitOutput // open output files by name
+	names  map[string]string       // lowercase file name -> file name, for case-insensitive file systems
+	Counts map[string]int          // games written to each file
+}
+
+// NewSplitter creates a splitter writing to dir. The validator normalizes player names and
+// formats games when canonical is set; count is the number of games per file for SplitByCount.
+func NewSplitter(validator *PGNValidator, mode SplitMode, count int, dir string, canonical bool) *Splitter {
+	return &Splitter{
+		validator: validator,
+		mode:      mode,
+		count:     count,
+		dir:       dir,
+		canonical: canonical,
+		open:      make(map[string]*splitOutput),
+		names:     make(map[string]string),
+		Counts:    make(map[string]int),
+	}
+}
+
+// fileNamePart turns a tag value into a portable file name: letters, digits, '-' and '.' are
+// kept, other runs of characters become '_'. Unknown values give "unknown".
+func fileNamePart(value string) string {
+	var sb strings.Builder
+	underscore := false
+	for _, r := range strings.TrimSpace(value) {
+		if r == '-' || r == '.' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127 {
+			sb.WriteRune(r)
+			underscore = false
+		} else if !underscore {
+			sb.WriteByte('_')
+			underscore = true
+		}
+	}
+	name := strings.Trim(sb.String(), "_.")
+	if name == "" || name == "-" {
+		return "unknown"
+	}
+	return name
+}
+
+// outputNames returns the names of the files a game is written to
+func (s *Splitter) outputNames(g *Game, index int, base string) []string {
+	var parts []string
+	switch s.mode {
+	case SplitByEvent:
+		parts = []string{fileNamePart(collapseSpaces(g.Tag("Event")))}
+	case SplitByPlayer:
+		for _, name := range []string{g.Tag("White"), g.Tag("Black")} {
+			parts = append(parts, fileNamePart(s.validator.normalizePlayerName(name)))
+		}
+		if parts[0] == parts[1] {
+			parts = parts[:1]
+		}
+	case SplitByMonth:
+		month := "unknown"
+		if matches := monthPattern.FindStringSubmatch(strings.TrimSpace(g.Tag("Date"))); matches != nil {
+			month = matches[1] + "-" + matches[2]
+		}
+		parts = []string{month}
+	case SplitByCount:
+		parts = []string{fmt.Sprintf("%s_%04d", base, index/s.count+1)}
+	}
+
+	names := make([]string, len(parts))
+	for i, part := range parts {
+		name := part + ".pgn"
+		if existing, ok := s.names[strings.ToLower(name)]; ok {
+			name = existing
+		} else {
+			s.names[strings.ToLower(name)] = name
+		}
+		names[i] = name
+	}
+	return names
+}
+
+// output returns the open output file with the given name, creating it on first use
+func (s *Splitter) output(name string) (*splitOutput, error) {
+	if out, ok := s.open[name]; ok {
+		return out, nil
+	}
+	if len(s.open) >= maxOpenSplitFiles {
+		if err := s.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	// Files written before are reopened in append mode, new files replace existing ones
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if _, written := s.Counts[name]; written {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(filepath.Join(s.dir, name), flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create output file: %v", err)
+	}
+	out := &splitOutput{file: file, writer: bufio.NewWriterSize(file, 64*1024)}
+	s.open[name] = out
+	return out, nil
+}
+
+// SplitFile writes every game of a PGN file to its output files. Files of SplitByCount are
+// named after the input file, e.g. twic1617_0001.pgn.
+func (s *Splitter) SplitFile(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("cannot open input file: %v", err)
+	}
+	defer file.Close()
+
+	base := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	scanner := NewGameScanner(file)
+	index := 0
+	for scanner.Scan() {
+		game := scanner.Game()
+		if !hasContent(game) {
+			continue
+		}
+
+		for _, name := range s.outputNames(game, index, base) {
+			out, err := s.output(name)
+			if err != nil {
+				return err
+			}
+			if s.canonical {
+				_, err = out.writer.WriteString(s.validator.formatGame(game))
+			} else {
+				err = writeGameLines(out.writer, game, scanner.LineEnding())
+			}
+			if err != nil {
+				return fmt.Errorf("error writing: %v", err)
+			}
+			s.Counts[name]++
+		}
+		index++
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading: %v", err)
+	}
+	return nil
+}
+
+// Close flushes and closes the open output files
+func (s *Splitter) Close() error {
+	var firstErr error
+	for name, out := range s.open {
+		if err := out.writer.Flush(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("error writing: %v", err)
+		}
+		if err := out.file.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("error writing: %v", err)
+		}
+		delete(s.open, name)
+	}
+	return firstErr
+}
+
+// Files returns the names of the output files in ASCII order
+func (s *Splitter) Files() []string {
+	names := make([]string, 0, len(s.Counts))
+	for name := range s.Counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WriteMergedFiles writes all games of the input files to w, one after the other, separated
+// by one blank line and with the line endings of the first file. The lines of the games are
+// copied unchanged or, if canonical is set, written in PGN export format. It returns the
+// number of games written.
+func (v *PGNValidator) WriteMergedFiles(inputFiles []string, w io.Writer, canonical bool) (int, error) {
+	written, _, err := v.writeGames(inputFiles, w, canonical, func(*Game) bool { return true })
+	return written, err
+}
@@ -0,0 +1,117 @@
+package pgn
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const splitContent = `[Event "Open A"]
+[Date "2023.05.02"]
+[White "Carlsen, Magnus"]
+[Black "Caruana, Fabiano"]
+[Result "1-0"]
+
+1. e4 e5 1-0
+
+[Event "Open B"]
+[Date "2023.06.??"]
+[White "Caruana, Fabiano"]
+[Black "Nepomniachtchi, Ian"]
+[Result "*"]
+
+1. d4   d5 *
+
+
+[Event "Open A"]
+[Date "????.??.??"]
+[White "Carlsen, Magnus"]
+[Black "Nepomniachtchi, Ian"]
+[Result "1/2-1/2"]
+
+1. c4 1/2-1/2
+`
+
+func TestSplitFile(t *testing.T) {
+	tmpFile := createTempFile(t, splitContent)
+	defer os.Remove(tmpFile)
+
+	tests := []struct {
+		mode     SplitMode
+		expected map[string]int
+	}{
+		{SplitByEvent, map[string]int{"Open_A.pgn": 2, "Open_B.pgn": 1}},
+		{SplitByPlayer, map[string]int{"Carlsen_Magnus.pgn": 2, "Caruana_Fabiano.pgn": 2, "Nepomniachtchi_Ian.pgn": 2}},
+		{SplitByMonth, map[string]int{"2023-05.pgn": 1, "2023-06.pgn": 1, "unknown.pgn": 1}},
+		{SplitByCount, map[string]int{"games_0001.pgn": 2, "games_0002.pgn": 1}},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		input := filepath.Join(dir, "games.pgn")
+		if err := os.WriteFile(input, []byte(splitContent), 0644); err != nil {
+			t.Fatalf("Failed to write input file: %v", err)
+		}
+
+		splitter := NewSplitter(NewPGNValidator(), tt.mode, 2, dir, false)
+		if err := splitter.SplitFile(input); err != nil {
+			t.Fatalf("SplitFile failed: %v", err)
+		}
+		if err := splitter.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		if !reflect.DeepEqual(splitter.Counts, tt.expected) {
+			t.Errorf("Mode %d: expected files %v, got %v", tt.mode, tt.expected, splitter.Counts)
+		}
+	}
+}
+
+func TestSplitMergeRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "games.pgn")
+	if err := os.WriteFile(input, []byte(splitContent), 0644); err != nil {
+		t.Fatalf("Failed to write input file: %v", err)
+	}
+
+	splitter := NewSplitter(NewPGNValidator(), SplitByCount, 1, dir, false)
+	if err := splitter.SplitFile(input); err != nil {
+		t.Fatalf("SplitFile failed: %v", err)
+	}
+	if err := splitter.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	var files []string
+	for _, name := range splitter.Files() {
+		files = append(files, filepath.Join(dir, name))
+	}
+	var out bytes.Buffer
+	written, err := NewPGNValidator().WriteMergedFiles(files, &out, false)
+	if err != nil {
+		t.Fatalf("WriteMergedFiles failed: %v", err)
+	}
+
+	// Game lines are kept unchanged, separated by exactly one blank line
+	expected := bytes.Replace([]byte(splitContent), []byte("*\n\n\n"), []byte("*\n\n"), 1)
+	expected = append(expected, '\n')
+	if written != 3 || !bytes.Equal(out.Bytes(), expected) {
+		t.Errorf("Expected 3 games, got %d:\n%s", written, out.String())
+	}
+}
+
+func TestFileNamePart(t *testing.T) {
+	tests := map[string]string{
+		"Carlsen, Magnus":      "Carlsen_Magnus",
+		"Tata Steel / Group A": "Tata_Steel_Group_A",
+		"?":                    "unknown",
+		"  ../etc  ":           "etc",
+		"Åland Open 2023":      "Åland_Open_2023",
+	}
+	for value, expected := range tests {
+		if got := fileNamePart(value); got != expected {
+			t.Errorf("fileNamePart(%q): expected %q, got %q", value, expected, got)
+		}
+	}
+}
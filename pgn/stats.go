@@ -0,0 +1,194 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// resultKeys is the order in which results are reported
+var resultKeys = []string{"1-0", "0-1", "1/2-1/2", "*", "other"}
+
+// Stats holds statistics about the games of one or more PGN files
+type Stats struct {
+	Games        int            `json:"games"`
+	Players      int            `json:"players"`
+	Events       int            `json:"events"`
+	Results      map[string]int `json:"results"`
+	AveragePlies float64        `json:"average_plies"`
+	ECO          map[string]int `json:"eco"`
+	WithoutECO   int            `json:"without_eco"`
+	FirstDate    string         `json:"first_date,omitempty"`
+	LastDate     string         `json:"last_date,omitempty"`
+	Errors       int            `json:"errors"`
+	ErrorsByRule map[string]int `json:"errors_by_rule"`
+
+	validator *PGNValidator
+	players   map[string]bool
+	events    map[string]bool
+	plies     int
+}
+
+// NewStats creates empty statistics. The validator normalizes player names and dates.
+func NewStats(validator *PGNValidator) *Stats {
+	return &Stats{
+		Results:      make(map[string]int),
+		ECO:          make(map[string]int),
+		ErrorsByRule: make(map[string]int),
+		validator:    validator,
+		players:      make(map[string]bool),
+		events:       make(map[string]bool),
+	}
+}
+
+// AddGame adds a game to the statistics
+func (s *Stats) AddGame(g *Game) {
+	if !hasContent(g) {
+		return
+	}
+	s.Games++
+
+	for _, name := range []string{g.Tag("White"), g.Tag("Black")} {
+		if name = s.validator.normalizePlayerName(name); name != "" && name != "?" && name != "-" {
+			s.players[name] = true
+		}
+	}
+	s.Players = len(s.players)
+
+	if event := collapseSpaces(g.Tag("Event")); event != "" && event != "?" {
+		s.events[event] = true
+	}
+	s.Events = len(s.events)
+
+	result := normalizeResult(g.Tag("Result"))
+	if result == "" {
+		result = "other"
+	}
+	s.Results[result]++
+
+	if eco := strings.ToUpper(strings.TrimSpace(g.Tag("ECO"))); eco != "" && eco != "?" {
+		s.ECO[eco]++
+	} else {
+		s.WithoutECO++
+	}
+
+	if date := s.validator.fullDate(strings.TrimSpace(g.Tag("Date"))); date != "" {
+		if s.FirstDate == "" || date < s.FirstDate {
+			s.FirstDate = date
+		}
+		if date > s.LastDate {
+			s.LastDate = date
+		}
+	}
+
+	s.plies += mainlinePlies(g)
+	s.AveragePlies = float64(s.plies) / float64(s.Games)
+}
+
+// AddErrors adds validation errors to the counts by rule
+func (s *Stats) AddErrors(errors []ValidationError) {
+	for _, err := range errors {
+		s.Errors++
+		s.ErrorsByRule[err.Rule]++
+	}
+}
+
+// mainlinePlies counts the mainline moves of a game without replaying them
+func mainlinePlies(g *Game) int {
+	depth, plies := 0, 0
+	for _, tok := range tokenizeMovetext(g.Movetext(), g.MovetextLine()) {
+		switch tok.Kind {
+		case TokenVariationStart:
+			depth++
+		case TokenVariationEnd:
+			if depth > 0 {
+				depth--
+			}
+		case TokenMove:
+			if depth == 0 {
+				plies++
+			}
+		}
+	}
+	return plies
+}
+
+// sortedCounts returns the keys of a count map, most frequent first, then in ASCII order
+func sortedCounts(counts map[string]int) []string {
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// percent formats n as a percentage of total
+func percent(n, total int) string {
+	if total == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", float64(n)*100/float64(total))
+}
+
+// WriteText writes the statistics as a human-readable report
+func (s *Stats) WriteText(w io.Writer) error {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "  Games:          %d\n", s.Games)
+	fmt.Fprintf(&sb, "  Players:        %d\n", s.Players)
+	fmt.Fprintf(&sb, "  Events:         %d\n", s.Events)
+	if s.FirstDate != "" {
+		fmt.Fprintf(&sb, "  Date range:     %s - %s\n", s.FirstDate, s.LastDate)
+	}
+	fmt.Fprintf(&sb, "  Average length: %.1f moves\n", s.AveragePlies/2)
+
+	sb.WriteString("\nResults:\n")
+	for _, result := range resultKeys {
+		if n := s.Results[result]; n > 0 || result != "other" {
+			fmt.Fprintf(&sb, "  %-8s %7d  %6s\n", result, n, percent(n, s.Games))
+		}
+	}
+
+	if len(s.ECO) > 0 {
+		volumes := make(map[string]int)
+		for eco, n := range s.ECO {
+			volumes[eco[:1]] += n
+		}
+		sb.WriteString("\nECO volumes:\n")
+		for _, volume := range []string{"A", "B", "C", "D", "E"} {
+			fmt.Fprintf(&sb, "  %-8s %7d  %6s\n", volume, volumes[volume], percent(volumes[volume], s.Games))
+		}
+		if s.WithoutECO > 0 {
+			fmt.Fprintf(&sb, "  %-8s %7d  %6s\n", "none", s.WithoutECO, percent(s.WithoutECO, s.Games))
+		}
+
+		sb.WriteString("\nMost played openings:\n")
+		for i, eco := range sortedCounts(s.ECO) {
+			if i == 10 {
+				break
+			}
+			fmt.Fprintf(&sb, "  %-8s %7d  %6s\n", eco, s.ECO[eco], percent(s.ECO[eco], s.Games))
+		}
+	}
+
+	fmt.Fprintf(&sb, "\nErrors: %d\n", s.Errors)
+	for _, rule := range sortedCounts(s.ErrorsByRule) {
+		fmt.Fprintf(&sb, "  %-16s %7d\n", rule, s.ErrorsByRule[rule])
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,85 @@
+package pgn
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	content := `[Event "Open"]
+[Date "2023.05.02"]
+[White "Carlsen, Magnus"]
+[Black "Caruana, Fabiano"]
+[Result "1-0"]
+[ECO "C65"]
+
+1. e4 e5 2. Nf3 Nc6 (2... d6 3. d4) 3. Bb5 1-0
+
+[Event "Open"]
+[Date "2023-05-01"]
+[White "Caruana, Fabiano"]
+[Black "Nepomniachtchi, Ian"]
+[Result "1/2-1/2"]
+[ECO "C65"]
+
+1. e4 e5 1/2-1/2
+
+[Event "Blitz"]
+[Date "????.??.??"]
+[White "Magnus Carlsen"]
+[Black "?"]
+[Result "½-½"]
+
+1. d4 d5 2. c4 e6 3. Nc3 1/2-1/2
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	validator := NewPGNValidator()
+	stats := NewStats(validator)
+	errors, _ := validator.ValidateFileWith(tmpFile, ValidateOptions{Visit: stats.AddGame})
+	stats.AddErrors(errors)
+
+	if stats.Games != 3 || stats.Players != 4 || stats.Events != 2 {
+		t.Errorf("Expected 3 games, 4 players, 2 events, got %d, %d, %d", stats.Games, stats.Players, stats.Events)
+	}
+	if expected := map[string]int{"1-0": 1, "1/2-1/2": 2}; !reflect.DeepEqual(stats.Results, expected) {
+		t.Errorf("Expected results %v, got %v", expected, stats.Results)
+	}
+	if stats.ECO["C65"] != 2 || stats.WithoutECO != 1 {
+		t.Errorf("Unexpected ECO counts %v, %d without ECO", stats.ECO, stats.WithoutECO)
+	}
+	if stats.FirstDate != "2023.05.01" || stats.LastDate != "2023.05.02" {
+		t.Errorf("Expected date range 2023.05.01 - 2023.05.02, got %s - %s", stats.FirstDate, stats.LastDate)
+	}
+	if stats.AveragePlies != 4 {
+		t.Errorf("Expected 4 plies on average, got %v", stats.AveragePlies)
+	}
+	expectedErrors := map[string]int{RuleDate: 1, RuleResult: 1, RulePlayerName: 1, RulePlayerSpelling: 1}
+	if !reflect.DeepEqual(stats.ErrorsByRule, expectedErrors) || stats.Errors != 4 {
+		t.Errorf("Expected errors %v, got %d: %v", expectedErrors, stats.Errors, stats.ErrorsByRule)
+	}
+
+	var text bytes.Buffer
+	if err := stats.WriteText(&text); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	for _, expected := range []string{"Games:          3", "Date range:     2023.05.01 - 2023.05.02", "Average length: 2.0 moves", "C65"} {
+		if !strings.Contains(text.String(), expected) {
+			t.Errorf("Expected report to contain %q, got:\n%s", expected, text.String())
+		}
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded Stats
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Games != 3 || decoded.ErrorsByRule[RuleDate] != 1 {
+		t.Errorf("Unexpected JSON output %s", data)
+	}
+}
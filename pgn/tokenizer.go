@@ -0,0 +1,183 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import "strings"
+
+// TokenKind identifies the kind of a movetext token
+type TokenKind int
+
+const (
+	TokenMoveNumber     TokenKind = iota // "12." or "12..."
+	TokenMove                            // SAN move, possibly with suffix annotations ("Nf3!?")
+	TokenNAG                             // numeric annotation glyph ("$14") or standalone suffix ("!?")
+	TokenComment                         // brace comment, Text holds the content without braces
+	TokenLineComment                     // rest-of-line comment, Text holds the content after ';'
+	TokenVariationStart                  // "("
+	TokenVariationEnd                    // ")"
+	TokenResult                          // game termination marker
+	TokenEscape                          // escaped line starting with '%'
+	TokenUnknown                         // anything else
+)
+
+// Token is a lexical element of movetext. Positions are 1-based line numbers and
+// 0-based byte offsets; End points just past the last byte of the token.
+type Token struct {
+	Kind         TokenKind
+	Text         string
+	Line, Col    int
+	EndLine      int
+	EndCol       int
+	Unterminated bool // brace comment still open at the end of the movetext
+}
+
+// isSymbolChar reports whether c can continue a PGN symbol (moves, results)
+func isSymbolChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		strings.IndexByte("_+#=:-/", c) >= 0
+}
+
+// isResultToken reports whether text is a game termination marker
+func isResultToken(text string) bool {
+	return text == "1-0" || text == "0-1" || text == "1/2-1/2" || text == "*"
+}
+
+// Tokens splits the movetext of a game into tokens
+func (g *Game) Tokens() []Token {
+	return tokenizeMovetext(g.Movetext(), g.MovetextLine())
+}
+
+// tokenizeMovetext splits movetext lines into tokens. firstLine is the line number of lines[0].
+func tokenizeMovetext(lines []string, firstLine int) []Token {
+	tokens := []Token{}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		lineNumber := firstLine + i
+
+		// Escape mechanism: a '%' in the first column hides the whole line
+		if strings.HasPrefix(line, "%") {
+			tokens = append(tokens, Token{Kind: TokenEscape, Text: line[1:], Line: lineNumber, Col: 0, EndLine: lineNumber, EndCol: len(line)})
+			continue
+		}
+
+		col := 0
+		for col < len(line) {
+			c := line[col]
+			start := col
+
+			switch {
+			case c == ' ' || c == '\t' || c == '\r':
+				col++
+
+			case c == '{':
+				// Brace comments may span several lines
+				tok := Token{Kind: TokenComment, Line: lineNumber, Col: start}
+				var text strings.Builder
+				col++
+				for {
+					end := strings.IndexByte(line[col:], '}')
+					if end >= 0 {
+						text.WriteString(line[col : col+end])
+						col += end + 1
+						break
+					}
+					text.WriteString(line[col:])
+					if i+1 >= len(lines) {
+						tok.Unterminated = true
+						col = len(line)
+						break
+					}
+					text.WriteByte('\n')
+					i++
+					line = lines[i]
+					lineNumber = firstLine + i
+					col = 0
+				}
+				tok.Text = text.String()
+				tok.EndLine, tok.EndCol = lineNumber, col
+				tokens = append(tokens, tok)
+
+			case c == ';':
+				tokens = append(tokens, Token{Kind: TokenLineComment, Text: line[col+1:], Line: lineNumber, Col: start, EndLine: lineNumber, EndCol: len(line)})
+				col = len(line)
+
+			case c == '(':
+				col++
+				tokens = append(tokens, Token{Kind: TokenVariationStart, Text: "(", Line: lineNumber, Col: start, EndLine: lineNumber, EndCol: col})
+
+			case c == ')':
+				col++
+				tokens = append(tokens, Token{Kind: TokenVariationEnd, Text: ")", Line: lineNumber, Col: start, EndLine: lineNumber, EndCol: col})
+
+			case c == '*':
+				col++
+				tokens = append(tokens, Token{Kind: TokenResult, Text: "*", Line: lineNumber, Col: start, EndLine: lineNumber, EndCol: col})
+
+			case c == '$':
+				col++
+				for col < len(line) && line[col] >= '0' && line[col] <= '9' {
+					col++
+				}
+				tokens = append(tokens, Token{Kind: TokenNAG, Text: line[start:col], Line: lineNumber, Col: start, EndLine: lineNumber, EndCol: col})
+
+			case c == '!' || c == '?':
+				for col < len(line) && (line[col] == '!' || line[col] == '?') {
+					col++
+				}
+				tokens = append(tokens, Token{Kind: TokenNAG, Text: line[start:col], Line: lineNumber, Col: start, EndLine: lineNumber, EndCol: col})
+
+			case c >= '0' && c <= '9':
+				// Move number indication: digits followed by one or more periods
+				for col < len(line) && line[col] >= '0' && line[col] <= '9' {
+					col++
+				}
+				if col < len(line) && line[col] == '.' {
+					for col < len(line) && line[col] == '.' {
+						col++
+					}
+					tokens = append(tokens, Token{Kind: TokenMoveNumber, Text: line[start:col], Line: lineNumber, Col: start, EndLine: lineNumber, EndCol: col})
+					break
+				}
+				col = start
+				fallthrough
+
+			case isSymbolChar(c):
+				for col < len(line) && isSymbolChar(line[col]) {
+					col++
+				}
+				// Suffix annotations stay attached to the move
+				for col < len(line) && (line[col] == '!' || line[col] == '?') {
+					col++
+				}
+				text := line[start:col]
+				kind := TokenMove
+				if isResultToken(text) {
+					kind = TokenResult
+				}
+				tokens = append(tokens, Token{Kind: kind, Text: text, Line: lineNumber, Col: start, EndLine: lineNumber, EndCol: col})
+
+			default:
+				for col < len(line) && !strings.ContainsRune(" \t\r{};()$", rune(line[col])) {
+					col++
+				}
+				if col == start {
+					col++
+				}
+				tokens = append(tokens, Token{Kind: TokenUnknown, Text: line[start:col], Line: lineNumber, Col: start, EndLine: lineNumber, EndCol: col})
+			}
+		}
+	}
+
+	return tokens
+}
+
+// splitSuffixAnnotation separates trailing "!" and "?" annotations from a move
+func splitSuffixAnnotation(move string) (string, string) {
+	core := strings.TrimRight(move, "!?")
+	return core, move[len(core):]
+}
@@ -0,0 +1,953 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package pgn
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Pre-compiled regex patterns for better performance
+var (
+	// tagPattern matches PGN tags in format [TagName "Value"]
+	// Groups: (1) tag name (word chars), (2) tag value (any chars)
+	tagPattern = regexp.MustCompile(`^\[(\w+)\s+"(.*)"\]$`)
+
+	// correctDatePattern matches dates in correct PGN format: YYYY.MM.DD
+	// Matches exactly 4 digits, dot, 2 digits, dot, 2 digits
+	correctDatePattern = regexp.MustCompile(`^\d{4}\.\d{2}\.\d{2}$`)
+
+	// wildcardDatePattern matches unknown dates in PGN format: ????.??.??
+	// Matches exactly 4 question marks, dot, 2 question marks, dot, 2 question marks
+	wildcardDatePattern = regexp.MustCompile(`^\?{4}\.\?{2}\.\?{2}$`)
+
+	// validMovePattern checks if line contains only valid PGN move characters
+	// Allows: letters, numbers, spaces, +#=-!?().*/{}$ (standard PGN notation and NAGs)
+	validMovePattern = regexp.MustCompile(`^[a-zA-Z0-9\s\+\#\=\-\!\?\(\)\.\*\/\{\}\$]+$`)
+
+	// movePattern extracts move numbers and moves from PGN notation
+	// Groups: (1) move number, (2) white's move, (3) black's move (optional)
+	// Matches: "1. e4 e5" or "23. Nf3"
+	movePattern = regexp.MustCompile(`(\d+)\.\s*([^\s]+)(?:\s+([^\s]+))?`)
+
+	// blackMoveNumberPattern matches a move number indication for Black's move: "12..."
+	blackMoveNumberPattern = regexp.MustCompile(`\d+\.\.\.`)
+
+	// promotionPattern matches pawn promotion moves
+	// Groups: (1) source file (optional for capture), (2) capture 'x' (optional), (3) destination square, (4) promoted piece (Q/R/B/N)
+	// Matches: "e8=Q" or "exd8=R"
+	promotionPattern = regexp.MustCompile(`^([a-h])?(x)?([a-h][1-8])=([QRBN])$`)
+
+	// piecePattern matches piece moves with optional disambiguation
+	// Groups: (1) piece (K/Q/R/B/N), (2) source file (optional), (3) source rank (optional), (4) capture 'x' (optional), (5) destination
+	// Matches: "Nf3", "Nbd7", "R1a3", "Qh4e1", "Bxe5"
+	piecePattern = regexp.MustCompile(`^([KQRBN])([a-h])?([1-8])?(x)?([a-h][1-8])$`)
+
+	// pawnPattern matches pawn moves with captures
+	// Groups: (1) source file, (2) capture 'x' (optional), (3) destination square
+	// Matches: "e4", "exd5"
+	pawnPattern = regexp.MustCompile(`^([a-h])(x)?([a-h][1-8])$`)
+
+	// simplePawnPattern matches simple pawn moves (destination only)
+	// Matches: "e4", "d5", "a6" (file a-h, rank 1-8)
+	simplePawnPattern = regexp.MustCompile(`^[a-h][1-8]$`)
+
+	// Date fixing patterns - used to auto-correct common date formats to PGN standard
+
+	// datePatternISO matches ISO 8601 date format: YYYY-MM-DD
+	// Groups: (1) year (4 digits), (2) month (2 digits), (3) day (2 digits)
+	datePatternISO = regexp.MustCompile(`^(\d{4})-(\d{2})-(\d{2})$`)
+
+	// datePatternDDMMYYYY matches European date format: DD/MM/YYYY
+	// Groups: (1) day (2 digits), (2) month (2 digits), (3) year (4 digits)
+	datePatternDDMMYYYY = regexp.MustCompile(`^(\d{2})/(\d{2})/(\d{4})$`)
+
+	// datePatternYYYYMMDD matches slash-separated date: YYYY/MM/DD
+	// Groups: (1) year (4 digits), (2) month (2 digits), (3) day (2 digits)
+	datePatternYYYYMMDD = regexp.MustCompile(`^(\d{4})/(\d{2})/(\d{2})$`)
+
+	// datePatternNoSep matches date without separators: YYYYMMDD
+	// Groups: (1) year (4 digits), (2) month (2 digits), (3) day (2 digits)
+	datePatternNoSep = regexp.MustCompile(`^(\d{4})(\d{2})(\d{2})$`)
+)
+
+// Rules identify the check reporting a validation error
+const (
+	RuleFile           = "file"
+	RuleTag            = "tag"
+	RuleDate           = "date"
+	RuleResult         = "result"
+	RuleCharacters     = "characters"
+	RuleParentheses    = "parentheses"
+	RuleBraces         = "braces"
+	RuleNesting        = "nesting"
+	RuleMoveNumber     = "move-number"
+	RuleMoveNotation   = "move-notation"
+	RuleNAG            = "nag"
+	RulePlayerName     = "player-name"
+	RulePlayerSpelling = "player-spelling"
+	RuleRound          = "round"
+	RuleEventSite      = "event-site"
+	RuleEventDate      = "event-date"
+	RuleBoard          = "board"
+	RulePairing        = "pairing"
+	RuleCommand        = "command"
+	RuleClock          = "clock"
+	RuleECO            = "eco"
+)
+
+// ValidationError represents a PGN validation error
+type ValidationError struct {
+	Line     int      `json:"line"`
+	Message  string   `json:"message"`
+	Rule     string   `json:"rule"`     // check reporting the error, one of the Rule constants
+	Severity Severity `json:"severity"` // SeverityError unless set
+}
+
+func (e ValidationError) String() string {
+	return fmt.Sprintf("Line %d: %s", e.Line, e.Message)
+}
+
+// PGNValidator handles PGN file validation
+type PGNValidator struct {
+	errors  []ValidationError
+	aliases map[string]string           // lowercase alias -> canonical player name
+	players map[string][]playerSpelling // player key -> spellings seen in the file
+	events  map[string]*eventGames      // Event tag -> games of the event
+
+	annotations AnnotationStyle  // how corrections write move annotations
+	openingTags bool             // corrections add or fix ECO, Opening and Variation tags
+	progress    ProgressReporter // receives the progress of validations and corrections, may be nil
+	cache       *Cache           // results of earlier validations, may be nil
+	games       *gameResults     // results of the games of the file being validated with the cache
+}
+
+// NewPGNValidator creates a new validator instance
+func NewPGNValidator() *PGNValidator {
+	return &PGNValidator{
+		errors:  make([]ValidationError, 0),
+		players: make(map[string][]playerSpelling),
+		events:  make(map[string]*eventGames),
+	}
+}
+
+// ValidateFile validates a PGN file and returns a list of errors
+func (v *PGNValidator) ValidateFile(filename string) []ValidationError {
+	errors, _ := v.validateFile(filename, ValidateOptions{})
+	return errors
+}
+
+// ValidateFileWith validates a PGN file like ValidateFile, with the options of ValidateReader.
+// It returns ErrTooManyErrors if validation stopped at opts.MaxErrors.
+func (v *PGNValidator) ValidateFileWith(filename string, opts ValidateOptions) ([]ValidationError, error) {
+	return v.validateFile(filename, opts)
+}
+
+// ErrTooManyErrors is returned when validation stops after ValidateOptions.MaxErrors errors
+var ErrTooManyErrors = errors.New("too many errors")
+
+// ValidateOptions configures ValidateReader
+type ValidateOptions struct {
+	Visit func(*Game) // called for each game as it is read, e.g. to collect statistics; may be nil
+
+	// OnError, if not nil, receives the errors as they are found instead of the returned list,
+	// so that memory does not grow with the number of errors. Errors of a game come in line
+	// order once the game is read; errors of checks spanning the whole input come last.
+	OnError func(ValidationError)
+
+	MaxErrors int // stop after this many errors, 0 for no limit
+}
+
+// ValidateReader validates the PGN games read from r and returns the same errors as
+// ValidateFile. It writes nothing itself: progress goes to the reporter set with SetProgress,
+// if any. If ctx is canceled, it stops reading
+// and returns the errors found so far with ctx.Err(); if opts.MaxErrors is reached, it stops
+// with ErrTooManyErrors.
+func (v *PGNValidator) ValidateReader(ctx context.Context, r io.Reader, opts ValidateOptions) ([]ValidationError, error) {
+	return v.validate(ctx, r, opts)
+}
+
+// ValidateString validates PGN text held in memory
+func (v *PGNValidator) ValidateString(content string) []ValidationError {
+	errors, _ := v.validate(context.Background(), strings.NewReader(content), ValidateOptions{})
+	return errors
+}
+
+// validateFile validates a PGN file
+func (v *PGNValidator) validateFile(filename string, opts ValidateOptions) ([]ValidationError, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		v.errors = []ValidationError{{
+			Line:    0,
+			Message: fmt.Sprintf("Cannot open file: %v", err),
+			Rule:    RuleFile,
+		}}
+		return v.reportErrors(opts), nil
+	}
+	defer file.Close()
+
+	if v.cache != nil {
+		return v.validateCached(file, opts)
+	}
+	return v.validate(context.Background(), file, opts)
+}
+
+// reportErrors passes the errors to opts.OnError, if set, and returns the errors left
+func (v *PGNValidator) reportErrors(opts ValidateOptions) []ValidationError {
+	if opts.OnError == nil {
+		return v.errors
+	}
+	for _, e := range v.errors {
+		opts.OnError(e)
+	}
+	v.errors = v.errors[:0]
+	return v.errors
+}
+
+// validate implements ValidateReader
+func (v *PGNValidator) validate(ctx context.Context, r io.Reader, opts ValidateOptions) ([]ValidationError, error) {
+	v.errors = make([]ValidationError, 0)
+	v.players = make(map[string][]playerSpelling)
+	v.events = make(map[string]*eventGames)
+
+	r, finish := v.startProgress("Validating", r)
+	if onError := opts.OnError; onError != nil && v.progress != nil {
+		// Errors written as they are found replace the progress display
+		opts.OnError = func(e ValidationError) {
+			v.progress.Clear()
+			onError(e)
+		}
+	}
+
+	scanner := NewGameScanner(r)
+	lineNumber := 0
+	reported, pending := 0, 0 // errors reported so far; index of the first error of the game
+
+	// flush reports the errors of the last game, in line order, and reports whether
+	// MaxErrors is reached
+	flush := func() bool {
+		sortErrors(v.errors[pending:])
+		limit := opts.MaxErrors > 0 && reported+len(v.errors)-pending >= opts.MaxErrors
+		if limit {
+			v.errors = v.errors[:pending+opts.MaxErrors-reported]
+		}
+		reported += len(v.errors) - pending
+		if opts.OnError != nil {
+			v.reportErrors(opts)
+		}
+		pending = len(v.errors)
+		return limit
+	}
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			flush()
+			finish(false)
+			return v.errors, err
+		}
+		game := scanner.Game()
+		lineNumber = game.StartLine + len(game.Lines) - 1
+
+		// Games validated before come from the cache
+		if !v.replayCachedGame(game) {
+			first := len(v.errors)
+			v.validateGame(game)
+			v.cacheGame(game, v.errors[first:])
+		}
+		v.recordEventGame(game)
+
+		if opts.Visit != nil {
+			opts.Visit(game)
+		}
+		if flush() {
+			finish(false)
+			sortErrors(v.errors)
+			return v.errors, ErrTooManyErrors
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		v.errors = append(v.errors, ValidationError{
+			Line:    lineNumber,
+			Message: fmt.Sprintf("Error reading file: %v", err),
+			Rule:    RuleFile,
+		})
+	}
+
+	// Checks spanning the whole file, reported in line order with the others
+	finish(true)
+	v.checkPlayerConsistency()
+	v.checkEvents()
+	if flush() {
+		sortErrors(v.errors)
+		return v.errors, ErrTooManyErrors
+	}
+	sortErrors(v.errors)
+
+	return v.errors, nil
+}
+
+// validateGame runs the checks of a single game
+func (v *PGNValidator) validateGame(game *Game) {
+	for i, line := range game.Lines {
+		lineNumber := game.StartLine + i
+		line = strings.TrimSpace(line)
+
+		// Skip empty lines
+		if line == "" {
+			continue
+		}
+
+		// Tags up to the movetext, as GameScanner found them: later lines starting with a
+		// bracket, e.g. "[%clk 0:01:02]" in a multi-line comment, are movetext
+		if i < game.MovetextStart {
+			v.validateTag(line, lineNumber, tagPattern)
+		} else {
+			v.validateMoves(line, lineNumber)
+		}
+	}
+
+	v.validateCommands(game)
+	v.validateECO(game)
+}
+
+// sortErrors sorts errors by line number, keeping the order of errors on the same line
+func sortErrors(errors []ValidationError) {
+	sort.SliceStable(errors, func(i, j int) bool {
+		return errors[i].Line < errors[j].Line
+	})
+}
+
+// validateTag validates a single PGN tag
+func (v *PGNValidator) validateTag(line string, lineNumber int, pattern *regexp.Regexp) {
+	matches := pattern.FindStringSubmatch(line)
+
+	if matches == nil {
+		v.errors = append(v.errors, ValidationError{
+			Line:    lineNumber,
+			Message: fmt.Sprintf("Malformed PGN tag: %s", line),
+			Rule:    RuleTag,
+		})
+		return
+	}
+
+	tagName := matches[1]
+	tagValue := matches[2]
+
+	// Specific validation for Date and EventDate tags (case-insensitive)
+	tagNameLower := strings.ToLower(tagName)
+	if tagNameLower == "date" || tagNameLower == "eventdate" {
+		v.validateDate(tagValue, lineNumber, line)
+	}
+
+	// Specific validation for Result tag (case-insensitive)
+	if tagNameLower == "result" {
+		v.validateResult(tagValue, lineNumber)
+	}
+
+	// Specific validation for Round tag (case-insensitive)
+	if tagNameLower == "round" {
+		v.validateRound(tagValue, lineNumber)
+	}
+
+	// Specific validation for player names (case-insensitive)
+	if tagNameLower == "white" || tagNameLower == "black" {
+		v.validatePlayerName(tagValue, lineNumber)
+	}
+}
+
+// validateDate validates and attempts to correct date format
+func (v *PGNValidator) validateDate(dateValue string, lineNumber int, originalLine string) {
+	// Correct format: YYYY.MM.DD
+	// Acceptable format with wildcards: ????.??.??
+	// If format is already correct, do nothing
+	if correctDatePattern.MatchString(dateValue) || wildcardDatePattern.MatchString(dateValue) {
+		return
+	}
+
+	// Attempt to correct the format
+	correctedDate, err := v.tryFixDate(dateValue)
+
+	if err != nil {
+		v.errors = append(v.errors, ValidationError{
+			Line:    lineNumber,
+			Message: fmt.Sprintf("Invalid date format: '%s'. Required format: YYYY.MM.DD (example: 2024.01.05)", dateValue),
+			Rule:    RuleDate,
+		})
+	} else {
+		v.errors = append(v.errors, ValidationError{
+			Line:     lineNumber,
+			Message:  fmt.Sprintf("Date auto-corrected: '%s' → '%s'", dateValue, correctedDate),
+			Rule:     RuleDate,
+			Severity: SeverityInfo,
+		})
+	}
+}
+
+// tryFixDate attempts to correct various date formats
+func (v *PGNValidator) tryFixDate(dateValue string) (string, error) {
+	// Remove spaces
+	dateValue = strings.TrimSpace(dateValue)
+
+	// YYYY-MM-DD (ISO 8601)
+	if matches := datePatternISO.FindStringSubmatch(dateValue); matches != nil {
+		return fmt.Sprintf("%s.%s.%s", matches[1], matches[2], matches[3]), nil
+	}
+
+	// DD/MM/YYYY or MM/DD/YYYY - assume DD/MM/YYYY for European format
+	if matches := datePatternDDMMYYYY.FindStringSubmatch(dateValue); matches != nil {
+		return fmt.Sprintf("%s.%s.%s", matches[3], matches[2], matches[1]), nil
+	}
+
+	// YYYY/MM/DD
+	if matches := datePatternYYYYMMDD.FindStringSubmatch(dateValue); matches != nil {
+		return fmt.Sprintf("%s.%s.%s", matches[1], matches[2], matches[3]), nil
+	}
+
+	// YYYYMMDD (no separators)
+	if matches := datePatternNoSep.FindStringSubmatch(dateValue); matches != nil {
+		return fmt.Sprintf("%s.%s.%s", matches[1], matches[2], matches[3]), nil
+	}
+
+	return "", fmt.Errorf("cannot correct date format")
+}
+
+// validateResult validates the Result tag
+func (v *PGNValidator) validateResult(resultValue string, lineNumber int) {
+	validResults := map[string]bool{
+		"1-0":     true, // White wins
+		"0-1":     true, // Black wins
+		"1/2-1/2": true, // Draw
+		"*":       true, // Game in progress or unknown result
+	}
+
+	if !validResults[resultValue] {
+		v.errors = append(v.errors, ValidationError{
+			Line:    lineNumber,
+			Message: fmt.Sprintf("Invalid result: '%s'. Valid values: 1-0, 0-1, 1/2-1/2, *", resultValue),
+			Rule:    RuleResult,
+		})
+	}
+}
+
+// validateMoves validates game moves
+func (v *PGNValidator) validateMoves(line string, lineNumber int) {
+	// Basic validation: check that line contains valid characters for moves
+	// Moves can contain: numbers, letters, +, #, =, -, !, ?, spaces, parentheses, braces, $ (NAGs)
+	// Embedded commands such as [%clk 0:03:12] have their own syntax, checked per game
+	if !validMovePattern.MatchString(commandPattern.ReplaceAllString(line, " ")) {
+		v.errors = append(v.errors, ValidationError{
+			Line:    lineNumber,
+			Message: "Invalid move format: disallowed characters found",
+			Rule:    RuleCharacters,
+		})
+	}
+
+	// Validate balanced parentheses for variations (parentheses inside comments are text)
+	if !v.checkBalancedDelimiters(v.removeComments(line), '(', ')') {
+		v.errors = append(v.errors, ValidationError{
+			Line:     lineNumber,
+			Message:  "Warning: Unbalanced parentheses in variations",
+			Rule:     RuleParentheses,
+			Severity: SeverityWarning,
+		})
+	}
+
+	// Validate balanced curly braces for comments
+	if !v.checkBalancedDelimiters(line, '{', '}') {
+		v.errors = append(v.errors, ValidationError{
+			Line:     lineNumber,
+			Message:  "Warning: Unbalanced curly braces in comments",
+			Rule:     RuleBraces,
+			Severity: SeverityWarning,
+		})
+	}
+
+	// Check for proper nesting of parentheses and braces
+	if !v.checkProperNesting(line) {
+		v.errors = append(v.errors, ValidationError{
+			Line:     lineNumber,
+			Message:  "Warning: Improper nesting of parentheses and braces",
+			Rule:     RuleNesting,
+			Severity: SeverityWarning,
+		})
+	}
+
+	// Validate numeric annotation glyphs
+	v.validateNAGs(line, lineNumber)
+
+	// Validate move notation and move numbers
+	v.validateMoveNotation(line, lineNumber)
+}
+
+// checkBalancedDelimiters checks if opening and closing delimiters are balanced
+func (v *PGNValidator) checkBalancedDelimiters(line string, open, close rune) bool {
+	count := 0
+	for _, char := range line {
+		switch char {
+		case open:
+			count++
+		case close:
+			count--
+			if count < 0 {
+				return false // Closing delimiter before opening
+			}
+		}
+	}
+	return count == 0 // All delimiters must be closed
+}
+
+// validateMoveNotation validates individual move notation and move numbers
+func (v *PGNValidator) validateMoveNotation(line string, lineNumber int) {
+	// Remove comments in curly braces
+	cleanLine := v.removeComments(line)
+
+	// Remove variations in parentheses
+	cleanLine = v.removeVariations(cleanLine)
+
+	// Remove numeric annotation glyphs, validated separately, and standalone "!?" annotations
+	cleanLine = nagPattern.ReplaceAllString(cleanLine, " ")
+	cleanLine = standaloneAnnotationPattern.ReplaceAllString(cleanLine, " ")
+
+	// Remove Black move number indications ("12..."), written after comments and variations
+	cleanLine = blackMoveNumberPattern.ReplaceAllString(cleanLine, " ")
+
+	// Extract moves and move numbers using regex
+	// Pattern per trovare numeri di mossa e le mosse stesse
+	matches := movePattern.FindAllStringSubmatch(cleanLine, -1)
+
+	expectedMoveNumber := 0
+
+	for _, match := range matches {
+		if len(match) < 3 {
+			continue
+		}
+
+		moveNumberStr := match[1]
+		whiteMove := match[2]
+		blackMove := ""
+		if len(match) > 3 && match[3] != "" {
+			blackMove = match[3]
+		}
+
+		// Parse move number
+		var moveNumber int
+		fmt.Sscanf(moveNumberStr, "%d", &moveNumber)
+
+		// Check sequential move numbers
+		if expectedMoveNumber == 0 {
+			expectedMoveNumber = moveNumber
+		} else {
+			expectedMoveNumber++
+			if moveNumber != expectedMoveNumber {
+				v.errors = append(v.errors, ValidationError{
+					Line:     lineNumber,
+					Message:  fmt.Sprintf("Warning: Move number out of sequence. Expected %d, found %d", expectedMoveNumber, moveNumber),
+					Rule:     RuleMoveNumber,
+					Severity: SeverityWarning,
+				})
+				expectedMoveNumber = moveNumber
+			}
+		}
+
+		// Validate white's move
+		if !v.isValidMoveNotation(whiteMove) {
+			v.errors = append(v.errors, ValidationError{
+				Line:     lineNumber,
+				Message:  fmt.Sprintf("Warning: Invalid move notation '%s' at move %d", whiteMove, moveNumber),
+				Rule:     RuleMoveNotation,
+				Severity: SeverityWarning,
+			})
+		}
+
+		// Validate black's move if present
+		if blackMove != "" && !v.isValidMoveNotation(blackMove) {
+			v.errors = append(v.errors, ValidationError{
+				Line:     lineNumber,
+				Message:  fmt.Sprintf("Warning: Invalid move notation '%s' at move %d", blackMove, moveNumber),
+				Rule:     RuleMoveNotation,
+				Severity: SeverityWarning,
+			})
+		}
+	}
+}
+
+// removeComments removes text in curly braces (comments)
+func (v *PGNValidator) removeComments(line string) string {
+	result := []rune{}
+	inComment := false
+
+	for _, char := range line {
+		if char == '{' {
+			inComment = true
+		} else if char == '}' {
+			inComment = false
+		} else if !inComment {
+			result = append(result, char)
+		}
+	}
+
+	return string(result)
+}
+
+// removeVariations removes text in parentheses (variations)
+func (v *PGNValidator) removeVariations(line string) string {
+	result := []rune{}
+	depth := 0
+
+	for _, char := range line {
+		if char == '(' {
+			depth++
+		} else if char == ')' {
+			if depth > 0 {
+				depth--
+			}
+		} else if depth == 0 {
+			result = append(result, char)
+		}
+	}
+
+	return string(result)
+}
+
+// isValidMoveNotation checks if a move follows correct PGN notation
+func (v *PGNValidator) isValidMoveNotation(move string) bool {
+	// Trim annotations like !, ?, !!, ??, !?, ?!
+	move = strings.TrimRight(move, "!?")
+
+	// Check for game result markers
+	if move == "1-0" || move == "0-1" || move == "1/2-1/2" || move == "*" {
+		return true
+	}
+
+	// Rimuove scacco e scacco matto prima di controllare castling
+	moveWithoutCheck := strings.TrimRight(move, "+#")
+
+	// Check for castling (with or without check/checkmate)
+	if moveWithoutCheck == "O-O" || moveWithoutCheck == "O-O-O" ||
+		moveWithoutCheck == "0-0" || moveWithoutCheck == "0-0-0" {
+		return true
+	}
+
+	// Pattern for valid moves:
+	// - Pieces: K, Q, R, B, N followed by coordinates
+	// - Pawns: only coordinates
+	// - Can contain: x (capture), = (promotion), + (check), # (checkmate)
+	// - Coordinates: a-h for files, 1-8 for ranks
+	// Remove check and checkmate at the end
+	move = strings.TrimRight(move, "+#")
+
+	// Pattern for promotion (ex: e8=Q)
+	if promotionPattern.MatchString(move) {
+		return true
+	}
+
+	// Pattern for moves of pieces with disambiguation
+	// Es: Nbd7, N1c3, Qh4e1, Raxb1
+	if piecePattern.MatchString(move) {
+		matches := piecePattern.FindStringSubmatch(move)
+		if len(matches) > 1 {
+			piece := matches[1]
+			// Verify that the piece is valid
+			if piece == "K" || piece == "Q" || piece == "R" || piece == "B" || piece == "N" {
+				return true
+			}
+		}
+	}
+
+	// Pawn move patterns
+	// Ex: e4, exd5, e8
+	if pawnPattern.MatchString(move) {
+		return true
+	}
+
+	// Simple pawn move patterns (only destination square)
+	// Ex: e4, d5, a6
+	if simplePawnPattern.MatchString(move) {
+		return true
+	}
+
+	// If the move does not match any valid pattern
+	return false
+}
+
+// checkProperNesting verifies that parentheses and braces are properly nested
+func (v *PGNValidator) checkProperNesting(line string) bool {
+	stack := []rune{}
+
+	for _, char := range line {
+		// Inside a comment only the closing brace is meaningful
+		if len(stack) > 0 && stack[len(stack)-1] == '{' && char != '}' {
+			continue
+		}
+
+		switch char {
+		case '(', '{':
+			stack = append(stack, char)
+		case ')':
+			if len(stack) == 0 || stack[len(stack)-1] != '(' {
+				return false
+			}
+			stack = stack[:len(stack)-1]
+		case '}':
+			if len(stack) == 0 || stack[len(stack)-1] != '{' {
+				return false
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	return len(stack) == 0
+}
+
+// correctGame returns the lines of a game with dates, player names, delimiters and annotations corrected
+func (v *PGNValidator) correctGame(g *Game) []string {
+	corrected := make([]string, g.MovetextStart)
+
+	for i, line := range g.Lines[:g.MovetextStart] {
+		correctedLine := line
+
+		// If it's a tag, check if corrections are needed
+		matches := tagPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches != nil {
+			tagName := matches[1]
+			tagValue := matches[2]
+
+			// Correct Date and EventDate tags if necessary (case-insensitive)
+			tagNameLower := strings.ToLower(tagName)
+			if tagNameLower == "date" || tagNameLower == "eventdate" {
+				correctedDate, err := v.tryFixDate(tagValue)
+				if err == nil {
+					// Replace with corrected date
+					correctedLine = fmt.Sprintf("[%s \"%s\"]", tagName, correctedDate)
+				}
+			}
+
+			// Normalize player names: stray whitespace and aliases
+			if tagNameLower == "white" || tagNameLower == "black" {
+				if name := v.normalizePlayerName(tagValue); name != tagValue {
+					correctedLine = fmt.Sprintf("[%s \"%s\"]", tagName, name)
+				}
+			}
+		}
+
+		corrected[i] = correctedLine
+	}
+
+	// Add or fix the opening tags from the moves played
+	if v.openingTags {
+		corrected = correctOpeningTags(g, corrected)
+	}
+
+	// Fix unbalanced parentheses and braces in the movetext, then rewrite annotations
+	return append(corrected, v.convertAnnotations(v.repairMovetext(g))...)
+}
+
+// WriteCorrectedFile reads the PGN file, applies corrections, and writes to output file
+func (v *PGNValidator) WriteCorrectedFile(inputFile, outputFile string) error {
+	// Open input file
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return fmt.Errorf("cannot open input file: %v", err)
+	}
+	defer file.Close()
+
+	// Create output file
+	outFile, err := os.Create(outputFile)
+	if err != nil {
+		return fmt.Errorf("cannot create output file: %v", err)
+	}
+
+	if err := v.CorrectGames(context.Background(), file, outFile); err != nil {
+		outFile.Close()
+		return err
+	}
+	// Closing may report the failure of a write, e.g. on a full disk
+	if err := outFile.Close(); err != nil {
+		return fmt.Errorf("error writing: %v", err)
+	}
+	return nil
+}
+
+// CorrectGames reads PGN games from r, applies corrections, and writes them to w, keeping the
+// line endings of the input. It stops with ctx.Err() when ctx is canceled.
+func (v *PGNValidator) CorrectGames(ctx context.Context, r io.Reader, w io.Writer) error {
+	r, finish := v.startProgress("Correcting", r)
+	err := v.correctGames(ctx, r, w)
+	finish(err == nil)
+	return err
+}
+
+// correctGames implements CorrectGames
+func (v *PGNValidator) correctGames(ctx context.Context, r io.Reader, w io.Writer) error {
+	scanner := NewGameScanner(r)
+
+	// Increase writer buffer size to 1MB
+	writer := bufio.NewWriterSize(w, 1024*1024)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		game := scanner.Game()
+
+		// Write lines (corrected or original), keeping the line endings of the input
+		for _, correctedLine := range v.correctGame(game) {
+			if _, err := writer.WriteString(correctedLine + scanner.LineEnding()); err != nil {
+				return fmt.Errorf("error writing: %v", err)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("error reading: %v", err)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("error writing: %v", err)
+	}
+	return nil
+}
+
+// WriteCorrectionDiff writes a unified diff between a PGN file and its corrected version.
+// It reports whether the corrections change anything.
+func (v *PGNValidator) WriteCorrectionDiff(inputFile string, w io.Writer) (bool, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return false, fmt.Errorf("cannot open input file: %v", err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(w)
+	changed := false
+
+	scanner := NewGameScanner(file)
+	var diff *unifiedDiff
+
+	for scanner.Scan() {
+		game := scanner.Game()
+		if diff == nil {
+			diff = newUnifiedDiff(writer, scanner.LineEnding())
+		}
+
+		corrected := v.correctGame(game)
+		if slices.Equal(game.Lines, corrected) {
+			if err := diff.addUnchanged(game.Lines); err != nil {
+				return changed, fmt.Errorf("error writing: %v", err)
+			}
+			continue
+		}
+
+		if !changed {
+			if err := diff.writeHeader(inputFile); err != nil {
+				return changed, fmt.Errorf("error writing: %v", err)
+			}
+			changed = true
+		}
+		if err := diff.add(diffLines(game.Lines, corrected)); err != nil {
+			return changed, fmt.Errorf("error writing: %v", err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return changed, fmt.Errorf("error reading: %v", err)
+	}
+
+	if diff != nil {
+		if err := diff.close(); err != nil {
+			return changed, fmt.Errorf("error writing: %v", err)
+		}
+	}
+
+	return changed, writer.Flush()
+}
+
+// CorrectFileInPlace applies corrections to a file by atomically replacing it.
+// If backup is true, the original content is kept in a ".bak" file next to it.
+func (v *PGNValidator) CorrectFileInPlace(filename string, backup bool) error {
+	fileInfo, err := os.Stat(filename)
+	if err != nil {
+		return fmt.Errorf("cannot get file info: %v", err)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("cannot open input file: %v", err)
+	}
+	defer file.Close()
+
+	// Write to a temporary file in the same directory so the final rename is atomic. It is
+	// removed on failure; after the rename, there is nothing left to remove.
+	tmpFile, err := os.CreateTemp(filepath.Dir(filename), "."+filepath.Base(filename)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary file: %v", err)
+	}
+	tmpName := tmpFile.Name()
+	defer os.Remove(tmpName)
+
+	if err := v.CorrectGames(context.Background(), file, tmpFile); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	// The corrected content must be on disk before it replaces the original, which may be
+	// the only copy
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("error writing: %v", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("error writing: %v", err)
+	}
+	if err := os.Chmod(tmpName, fileInfo.Mode().Perm()); err != nil {
+		return fmt.Errorf("cannot set file permissions: %v", err)
+	}
+
+	if backup {
+		if err := copyFile(filename, filename+".bak"); err != nil {
+			return fmt.Errorf("cannot create backup: %v", err)
+		}
+	}
+
+	if err := os.Rename(tmpName, filename); err != nil {
+		return fmt.Errorf("cannot replace file: %v", err)
+	}
+
+	return nil
+}
+
+// copyFile copies the content and permissions of src to dst
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	fileInfo, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileInfo.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
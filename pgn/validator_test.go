@@ -0,0 +1,476 @@
+package pgn
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewPGNValidator(t *testing.T) {
+	validator := NewPGNValidator()
+	if validator == nil {
+		t.Fatal("NewPGNValidator returned nil")
+	}
+	if validator.errors == nil {
+		t.Fatal("Validator errors slice is nil")
+	}
+}
+
+func TestValidateValidFile(t *testing.T) {
+	// Create a temporary valid PGN file
+	content := `[Event "Test"]
+[Site "Test"]
+[Date "2024.01.15"]
+[Round "1"]
+[White "Player1"]
+[Black "Player2"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 1-0
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	validator := NewPGNValidator()
+	errors := validator.ValidateFile(tmpFile)
+
+	if len(errors) != 0 {
+		t.Errorf("Expected 0 errors for valid file, got %d: %v", len(errors), errors)
+	}
+}
+
+func TestValidateInvalidDate(t *testing.T) {
+	// Create a temporary PGN file with invalid date
+	content := `[Event "Test"]
+[Site "Test"]
+[Date "2024-01-15"]
+[Round "1"]
+[White "Player1"]
+[Black "Player2"]
+[Result "1-0"]
+
+1. e4 e5 *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	validator := NewPGNValidator()
+	errors := validator.ValidateFile(tmpFile)
+
+	if len(errors) == 0 {
+		t.Error("Expected errors for invalid date format, got none")
+	}
+
+	// Check that the error message mentions date correction
+	found := false
+	for _, err := range errors {
+		if err.Line == 3 {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("Expected error on line 3 (date line)")
+	}
+}
+
+func TestValidateString(t *testing.T) {
+	content := `[Event "Test"]
+[Date "2024-01-15"]
+[Result "1-0"]
+
+1. e4 e5 *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	// The same errors as for the file
+	validator := NewPGNValidator()
+	expected := validator.ValidateFile(tmpFile)
+	got := validator.ValidateString(content)
+	if len(expected) == 0 || len(got) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, got)
+	}
+	for i := range got {
+		if got[i] != expected[i] {
+			t.Errorf("Error %d: expected %v, got %v", i, expected[i], got[i])
+		}
+	}
+}
+
+func TestValidateReader(t *testing.T) {
+	content := "[Event \"A\"]\n\n1. e4 *\n\n[Event \"B\"]\n[Date \"2024-01-15\"]\n\n1. d4 *\n"
+
+	var events []string
+	validator := NewPGNValidator()
+	errors, err := validator.ValidateReader(context.Background(), strings.NewReader(content), ValidateOptions{
+		Visit: func(g *Game) { events = append(events, g.Tag("Event")) },
+	})
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+	if len(errors) != 1 || errors[0].Line != 6 || errors[0].Rule != RuleDate {
+		t.Errorf("Expected the date error on line 6, got %v", errors)
+	}
+	if strings.Join(events, ",") != "A,B" {
+		t.Errorf("Expected games A and B to be visited, got %v", events)
+	}
+
+	// Canceling stops before the second game
+	ctx, cancel := context.WithCancel(context.Background())
+	errors, err = validator.ValidateReader(ctx, strings.NewReader(content), ValidateOptions{
+		Visit: func(*Game) { cancel() },
+	})
+	if err != context.Canceled || len(errors) != 0 {
+		t.Errorf("Expected cancellation without errors, got %v, %v", err, errors)
+	}
+}
+
+func TestTryFixDate(t *testing.T) {
+	validator := NewPGNValidator()
+
+	tests := []struct {
+		input      string
+		expected   string
+		shouldFail bool
+	}{
+		{"2024-01-15", "2024.01.15", false}, // ISO 8601
+		{"15/01/2024", "2024.01.15", false}, // DD/MM/YYYY
+		{"2024/01/15", "2024.01.15", false}, // YYYY/MM/DD
+		{"20240115", "2024.01.15", false},   // YYYYMMDD
+		{"invalid", "", true},               // Invalid format
+		{"not-a-date", "", true},            // Invalid format
+	}
+
+	for _, tt := range tests {
+		result, err := validator.tryFixDate(tt.input)
+		if tt.shouldFail {
+			if err == nil {
+				t.Errorf("Expected error for input '%s', got none", tt.input)
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Unexpected error for input '%s': %v", tt.input, err)
+			}
+			if result != tt.expected {
+				t.Errorf("For input '%s', expected '%s', got '%s'", tt.input, tt.expected, result)
+			}
+		}
+	}
+}
+
+func TestValidateResult(t *testing.T) {
+	validator := NewPGNValidator()
+
+	validResults := []string{"1-0", "0-1", "1/2-1/2", "*"}
+	for _, result := range validResults {
+		validator.errors = make([]ValidationError, 0)
+		validator.validateResult(result, 1)
+		if len(validator.errors) != 0 {
+			t.Errorf("Expected no errors for valid result '%s', got %d errors", result, len(validator.errors))
+		}
+	}
+
+	invalidResults := []string{"2-0", "1-1", "draw", ""}
+	for _, result := range invalidResults {
+		validator.errors = make([]ValidationError, 0)
+		validator.validateResult(result, 1)
+		if len(validator.errors) == 0 {
+			t.Errorf("Expected errors for invalid result '%s', got none", result)
+		}
+	}
+}
+
+func TestCheckBalancedDelimiters(t *testing.T) {
+	validator := NewPGNValidator()
+
+	tests := []struct {
+		line     string
+		open     rune
+		close    rune
+		expected bool
+	}{
+		{"(1. e4)", '(', ')', true},
+		{"(1. e4 (e5))", '(', ')', true},
+		{"(1. e4", '(', ')', false},
+		{"1. e4)", '(', ')', false},
+		{"{comment}", '{', '}', true},
+		{"{comment", '{', '}', false},
+	}
+
+	for _, tt := range tests {
+		result := validator.checkBalancedDelimiters(tt.line, tt.open, tt.close)
+		if result != tt.expected {
+			t.Errorf("For line '%s' with delimiters '%c' and '%c', expected %v, got %v",
+				tt.line, tt.open, tt.close, tt.expected, result)
+		}
+	}
+}
+
+func TestParenthesesInComments(t *testing.T) {
+	validator := NewPGNValidator()
+	validator.validateMoves("1. e4 {see (a) or 1) below} e5 {(} 2. Nf3", 1)
+	if len(validator.errors) != 0 {
+		t.Errorf("Expected parentheses inside comments to be ignored, got %v", validator.errors)
+	}
+}
+
+func TestIsValidMoveNotation(t *testing.T) {
+	validator := NewPGNValidator()
+
+	validMoves := []string{
+		"e4", "Nf3", "Bb5", "O-O", "O-O-O",
+		"Qh5+", "Qh4#", "e8=Q", "exd5",
+		"Nbd7", "R1a3", "1-0", "0-1", "1/2-1/2", "*",
+	}
+
+	for _, move := range validMoves {
+		if !validator.isValidMoveNotation(move) {
+			t.Errorf("Expected move '%s' to be valid, but it was marked as invalid", move)
+		}
+	}
+
+	invalidMoves := []string{
+		"Xe4", "i5", "a9", "Qj5",
+	}
+
+	for _, move := range invalidMoves {
+		if validator.isValidMoveNotation(move) {
+			t.Errorf("Expected move '%s' to be invalid, but it was marked as valid", move)
+		}
+	}
+}
+
+func TestWriteCorrectedFile(t *testing.T) {
+	// Create a temporary PGN file with invalid date
+	content := `[Event "Test"]
+[Date "2024-01-15"]
+[Result "1-0"]
+
+1. e4 e5 *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	outputFile := filepath.Join(os.TempDir(), "test_output.pgn")
+	defer os.Remove(outputFile)
+
+	validator := NewPGNValidator()
+	err := validator.WriteCorrectedFile(tmpFile, outputFile)
+	if err != nil {
+		t.Fatalf("WriteCorrectedFile failed: %v", err)
+	}
+
+	// Verify output file exists
+	if _, err := os.Stat(outputFile); os.IsNotExist(err) {
+		t.Fatal("Output file was not created")
+	}
+
+	// Read and verify content
+	data, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read output file: %v", err)
+	}
+
+	content_str := string(data)
+	// Check that date was corrected
+	if len(content_str) == 0 {
+		t.Error("Output file is empty")
+	}
+}
+
+func TestCorrectGames(t *testing.T) {
+	content := "[Event \"Test\"]\r\n[Date \"2024-01-15\"]\r\n[Result \"1-0\"]\r\n\r\n1. e4 e5 *\r\n"
+
+	var out strings.Builder
+	validator := NewPGNValidator()
+	if err := validator.CorrectGames(context.Background(), strings.NewReader(content), &out); err != nil {
+		t.Fatalf("CorrectGames failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "[Date \"2024.01.15\"]\r\n") {
+		t.Errorf("Expected the corrected date with CRLF line endings, got %q", out.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := validator.CorrectGames(ctx, strings.NewReader(content), &out); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWriteCorrectionDiff(t *testing.T) {
+	content := `[Event "Test"]
+[Date "2024-01-15"]
+[Result "1-0"]
+
+1. e4 e5 *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	var out bytes.Buffer
+	validator := NewPGNValidator()
+	changed, err := validator.WriteCorrectionDiff(tmpFile, &out)
+	if err != nil {
+		t.Fatalf("WriteCorrectionDiff failed: %v", err)
+	}
+	if !changed {
+		t.Fatal("Expected the date correction to be reported as a change")
+	}
+
+	name := strings.TrimPrefix(filepath.ToSlash(tmpFile), "/")
+	expected := "--- a/" + name + "\n+++ b/" + name + `
+@@ -1,5 +1,5 @@
+ [Event "Test"]
+-[Date "2024-01-15"]
++[Date "2024.01.15"]
+ [Result "1-0"]
+ 
+ 1. e4 e5 *
+`
+	if out.String() != expected {
+		t.Errorf("Unexpected diff:\n%s\nExpected:\n%s", out.String(), expected)
+	}
+}
+
+func TestCorrectFileInPlace(t *testing.T) {
+	content := "[Event \"Test\"]\r\n[Date \"2024/01/15\"]\r\n\r\n1. e4 e5 *\r\n"
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+	defer os.Remove(tmpFile + ".bak")
+
+	validator := NewPGNValidator()
+	if err := validator.CorrectFileInPlace(tmpFile, true); err != nil {
+		t.Fatalf("CorrectFileInPlace failed: %v", err)
+	}
+
+	data, err := os.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Failed to read corrected file: %v", err)
+	}
+	expected := "[Event \"Test\"]\r\n[Date \"2024.01.15\"]\r\n\r\n1. e4 e5 *\r\n"
+	if string(data) != expected {
+		t.Errorf("Expected corrected file %q, got %q", expected, string(data))
+	}
+
+	backup, err := os.ReadFile(tmpFile + ".bak")
+	if err != nil {
+		t.Fatalf("Failed to read backup file: %v", err)
+	}
+	if string(backup) != content {
+		t.Errorf("Expected backup to hold the original content, got %q", string(backup))
+	}
+}
+
+func TestCorrectFileInPlaceFailure(t *testing.T) {
+	// A directory can be opened but not read: the correction fails, leaving nothing behind
+	dir := t.TempDir()
+	target := filepath.Join(dir, "games.pgn")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewPGNValidator().CorrectFileInPlace(target, false); err == nil {
+		t.Fatal("Expected CorrectFileInPlace to fail")
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !entries[0].IsDir() {
+		t.Errorf("Expected only the original directory to remain, got %v", entries)
+	}
+}
+
+func TestCaseSensitiveTagNames(t *testing.T) {
+	// Test that tag names are case-insensitive
+	content := `[Event "Test"]
+[date "2024-01-15"]
+[result "1-0"]
+
+1. e4 e5 *
+`
+	tmpFile := createTempFile(t, content)
+	defer os.Remove(tmpFile)
+
+	validator := NewPGNValidator()
+	errors := validator.ValidateFile(tmpFile)
+
+	// Should detect the date error even with lowercase "date"
+	found := false
+	for _, err := range errors {
+		if err.Line == 2 {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Error("Expected validator to handle lowercase 'date' tag")
+	}
+}
+
+// Helper function to create temporary test files
+func createTempFile(t *testing.T, content string) string {
+	tmpFile, err := os.CreateTemp("", "test_*.pgn")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	if _, err := tmpFile.WriteString(content); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpFile.Name())
+		t.Fatalf("Failed to write to temp file: %v", err)
+	}
+
+	tmpFile.Close()
+	return tmpFile.Name()
+}
+
+func TestValidateReaderStreaming(t *testing.T) {
+	content := `[Event "A"]
+[Date "2024-01-15"]
+[White "Carlsen, Magnus"]
+
+1. e4 *
+
+[Event "A"]
+[Date "2024-01-16"]
+[White "Carlsen,Magnus"]
+
+1. d4 *
+`
+	validator := NewPGNValidator()
+	expected := validator.ValidateString(content)
+
+	// The same errors, games in order and whole-file checks last
+	var streamed []ValidationError
+	errors, err := validator.ValidateReader(context.Background(), strings.NewReader(content), ValidateOptions{
+		OnError: func(e ValidationError) { streamed = append(streamed, e) },
+	})
+	if err != nil || len(errors) != 0 {
+		t.Fatalf("Expected no returned errors, got %v, %v", errors, err)
+	}
+	if len(streamed) != len(expected) || len(streamed) < 3 {
+		t.Fatalf("Expected %v, got %v", expected, streamed)
+	}
+	if streamed[0].Line != 2 || streamed[1].Line != 8 || streamed[len(streamed)-1].Rule != RulePlayerSpelling {
+		t.Errorf("Unexpected order of streamed errors: %v", streamed)
+	}
+
+	// MaxErrors stops early
+	streamed = nil
+	_, err = validator.ValidateReader(context.Background(), strings.NewReader(content), ValidateOptions{
+		OnError:   func(e ValidationError) { streamed = append(streamed, e) },
+		MaxErrors: 1,
+	})
+	if err != ErrTooManyErrors || len(streamed) != 1 || streamed[0].Line != 2 {
+		t.Errorf("Expected to stop after the first error, got %v, %v", err, streamed)
+	}
+	errors, err = validator.ValidateReader(context.Background(), strings.NewReader(content), ValidateOptions{MaxErrors: 2})
+	if err != ErrTooManyErrors || len(errors) != 2 {
+		t.Errorf("Expected 2 returned errors, got %v, %v", err, errors)
+	}
+}
@@ -0,0 +1,129 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/schollz/progressbar/v3"
+	"golang.org/x/term"
+
+	"pgn_check/pgn"
+)
+
+// minProgressBarSize is the input size from which the terminal progress bar is drawn
+const minProgressBarSize = 1024 * 1024
+
+// newProgress returns the progress reporter selected by a -progress value: auto (a bar when
+// standard output is a terminal), always, never or json (events on standard error, for GUIs).
+// It returns nil when no progress is shown.
+func newProgress(mode string) (pgn.ProgressReporter, error) {
+	switch mode {
+	case "auto":
+		if !term.IsTerminal(int(os.Stdout.Fd())) {
+			return nil, nil
+		}
+		return &terminalProgress{}, nil
+	case "always":
+		return &terminalProgress{}, nil
+	case "never":
+		return nil, nil
+	case "json":
+		return &jsonProgress{encoder: json.NewEncoder(os.Stderr)}, nil
+	}
+	return nil, fmt.Errorf("unknown progress mode '%s' (expected auto, always, never or json)", mode)
+}
+
+// terminalProgress draws a progress bar on standard output for inputs larger than 1 MB
+type terminalProgress struct {
+	bar *progressbar.ProgressBar
+}
+
+func (p *terminalProgress) Start(task string, total int64) {
+	if total < minProgressBarSize {
+		return
+	}
+	p.bar = progressbar.NewOptions64(
+		total,
+		progressbar.OptionSetDescription(task),
+		progressbar.OptionSetWidth(40),
+		progressbar.OptionShowBytes(true),
+		progressbar.OptionUseIECUnits(false),
+		progressbar.OptionSetPredictTime(true),
+		progressbar.OptionShowCount(),
+	)
+}
+
+func (p *terminalProgress) Update(done int64) {
+	if p.bar != nil {
+		p.bar.Set64(done)
+	}
+}
+
+func (p *terminalProgress) Clear() {
+	if p.bar != nil {
+		p.bar.Clear()
+	}
+}
+
+func (p *terminalProgress) Finish(completed bool) {
+	if p.bar == nil {
+		return
+	}
+	// Complete the bar to 100%, or erase it if the task stopped early
+	if completed {
+		p.bar.Finish()
+		fmt.Println()
+	} else {
+		p.bar.Clear()
+		p.bar.Exit()
+	}
+	p.bar = nil
+}
+
+// progressEvent is a line written by jsonProgress
+type progressEvent struct {
+	Event     string `json:"event"` // start, progress or finish
+	Task      string `json:"task"`
+	Done      int64  `json:"done"`
+	Total     int64  `json:"total"`               // input size in bytes, 0 if unknown
+	Completed *bool  `json:"completed,omitempty"` // finish events only
+}
+
+// jsonProgress writes progress events as JSON lines, one per percent of the input at most
+type jsonProgress struct {
+	encoder *json.Encoder
+	task    string
+	done    int64
+	total   int64
+	percent int64
+}
+
+func (p *jsonProgress) Start(task string, total int64) {
+	p.task, p.done, p.total, p.percent = task, 0, total, 0
+	p.encoder.Encode(progressEvent{Event: "start", Task: task, Total: total})
+}
+
+func (p *jsonProgress) Update(done int64) {
+	p.done = done
+	if p.total > 0 {
+		percent := done * 100 / p.total
+		if percent == p.percent {
+			return
+		}
+		p.percent = percent
+	}
+	p.encoder.Encode(progressEvent{Event: "progress", Task: p.task, Done: done, Total: p.total})
+}
+
+func (p *jsonProgress) Clear() {}
+
+func (p *jsonProgress) Finish(completed bool) {
+	p.encoder.Encode(progressEvent{Event: "finish", Task: p.task, Done: p.done, Total: p.total, Completed: &completed})
+}
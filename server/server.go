@@ -0,0 +1,255 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+// Package server exposes PGN validation, correction and formatting over HTTP. It is the
+// service behind "pgn_check serve".
+//
+//	POST /validate  PGN body, answers JSON lines: one diagnostic per error, then a summary
+//	POST /fix       PGN body, answers the PGN with corrections applied
+//	POST /format    PGN body, answers the PGN in export format
+//	GET  /health    answers the status of the service as JSON
+//
+// Bodies may be compressed with Content-Encoding gzip or deflate.
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"pgn_check/pgn"
+)
+
+// Defaults of the Config limits left at zero
+const (
+	DefaultMaxBodySize = 32 * 1024 * 1024
+	DefaultTimeout     = time.Minute
+)
+
+// Config holds the limits of a Server and the validator it uses
+type Config struct {
+	MaxBodySize   int64                    // largest PGN body accepted, in bytes after decompression
+	Timeout       time.Duration            // longest time spent processing a request
+	MaxConcurrent int                      // requests processed at once (default: number of CPUs)
+	NewValidator  func() *pgn.PGNValidator // validator of each request (default: pgn.NewPGNValidator)
+}
+
+// Server is an http.Handler serving the validation endpoints
+type Server struct {
+	config Config
+	slots  chan struct{} // one element per request being processed
+	mux    *http.ServeMux
+}
+
+// New returns a Server with the given limits
+func New(config Config) *Server {
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = DefaultMaxBodySize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = runtime.NumCPU()
+	}
+	if config.NewValidator == nil {
+		config.NewValidator = pgn.NewPGNValidator
+	}
+
+	s := &Server{
+		config: config,
+		slots:  make(chan struct{}, config.MaxConcurrent),
+		mux:    http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /health", s.handleHealth)
+	s.mux.HandleFunc("POST /validate", s.process(s.validate))
+	s.mux.HandleFunc("POST /fix", s.process(s.fix))
+	s.mux.HandleFunc("POST /format", s.process(s.format))
+	return s
+}
+
+// ServeHTTP dispatches a request to its endpoint
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Health is the answer of GET /health
+type Health struct {
+	Status        string `json:"status"`         // always "ok"
+	Active        int    `json:"active"`         // requests being processed
+	MaxConcurrent int    `json:"max_concurrent"` // requests processed at once at most
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Health{Status: "ok", Active: len(s.slots), MaxConcurrent: cap(s.slots)})
+}
+
+// endpoint processes a PGN body read in full, writing its answer to w
+type endpoint func(ctx context.Context, r *http.Request, body io.Reader, w http.ResponseWriter)
+
+// process wraps an endpoint with the body size limit, the timeout and the concurrency limit
+func (s *Server) process(handle endpoint) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		deadline := time.Now().Add(s.config.Timeout)
+		ctx, cancel := context.WithDeadline(r.Context(), deadline)
+		defer cancel()
+
+		// The body is read before answering so that an oversized or corrupt body gets an
+		// error status instead of a truncated answer. It is read within the timeout, whatever
+		// the timeouts of the http.Server, and before taking a slot so that slow uploads do not
+		// hold one. Writers without deadlines, such as httptest.ResponseRecorder, are left as is.
+		http.NewResponseController(w).SetReadDeadline(deadline)
+		body, status, err := s.readBody(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		select {
+		case s.slots <- struct{}{}:
+			defer func() { <-s.slots }()
+		default:
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "too many requests being processed", http.StatusServiceUnavailable)
+			return
+		}
+		handle(ctx, r, bytes.NewReader(body), w)
+	}
+}
+
+// readBody returns the decompressed request body, or the status and error to answer with
+func (s *Server) readBody(w http.ResponseWriter, r *http.Request) ([]byte, int, error) {
+	limit := s.config.MaxBodySize
+	var body io.Reader = http.MaxBytesReader(w, r.Body, limit)
+
+	encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "", "identity":
+	case "gzip", "x-gzip", "deflate":
+		var decompressed io.ReadCloser
+		var err error
+		if encoding == "deflate" {
+			decompressed, err = zlib.NewReader(body)
+		} else {
+			decompressed, err = gzip.NewReader(body)
+		}
+		if err != nil {
+			return nil, readStatus(err), fmt.Errorf("cannot decompress body: %v", err)
+		}
+		defer decompressed.Close()
+		body = decompressed
+	default:
+		return nil, http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content encoding '%s' (expected gzip or deflate)", encoding)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, readStatus(err), fmt.Errorf("cannot read body: %v", err)
+	}
+	if int64(len(data)) > limit {
+		return nil, http.StatusRequestEntityTooLarge, fmt.Errorf("body larger than %d bytes", limit)
+	}
+	return data, 0, nil
+}
+
+// readStatus returns the status answering a failure to read the body
+func readStatus(err error) int {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return http.StatusRequestEntityTooLarge
+	}
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		return http.StatusRequestTimeout
+	}
+	return http.StatusBadRequest
+}
+
+// Diagnostic is a line of the answer of POST /validate reporting an error
+type Diagnostic struct {
+	Line     int          `json:"line"`
+	Rule     string       `json:"rule"`
+	Severity pgn.Severity `json:"severity"` // "error", "warning" or "info"
+	Message  string       `json:"message"`
+}
+
+// Summary is the last line of the answer of POST /validate
+type Summary struct {
+	Valid   bool   `json:"valid"`             // no diagnostic of severity error, validation complete
+	Errors  int    `json:"errors"`            // diagnostics written, of any severity
+	Stopped bool   `json:"stopped,omitempty"` // validation stopped at max_errors
+	Error   string `json:"error,omitempty"`   // validation did not complete, e.g. on timeout
+}
+
+// validate answers POST /validate[?max_errors=N], writing each diagnostic as it is found
+func (s *Server) validate(ctx context.Context, r *http.Request, body io.Reader, w http.ResponseWriter) {
+	var maxErrors int
+	if value := r.URL.Query().Get("max_errors"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			http.Error(w, fmt.Sprintf("invalid max_errors '%s'", value), http.StatusBadRequest)
+			return
+		}
+		maxErrors = n
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+	flusher := http.NewResponseController(w)
+	var summary Summary
+	invalid := false
+	_, err := s.config.NewValidator().ValidateReader(ctx, body, pgn.ValidateOptions{
+		MaxErrors: maxErrors,
+		OnError: func(e pgn.ValidationError) {
+			encoder.Encode(Diagnostic{Line: e.Line, Rule: e.Rule, Severity: e.Severity, Message: e.Message})
+			flusher.Flush()
+			summary.Errors++
+			invalid = invalid || e.Severity == pgn.SeverityError
+		},
+	})
+	switch {
+	case errors.Is(err, pgn.ErrTooManyErrors):
+		summary.Stopped = true
+	case errors.Is(err, context.DeadlineExceeded):
+		summary.Error = fmt.Sprintf("validation timed out after %v", s.config.Timeout)
+	case err != nil:
+		summary.Error = err.Error()
+	}
+	// Warnings and info do not make the PGN invalid, but a partial validation is not conclusive
+	summary.Valid = !invalid && !summary.Stopped && summary.Error == ""
+	encoder.Encode(summary)
+}
+
+// fix answers POST /fix
+func (s *Server) fix(ctx context.Context, r *http.Request, body io.Reader, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/x-chess-pgn")
+	abortOnError(s.config.NewValidator().CorrectGames(ctx, body, w))
+}
+
+// format answers POST /format
+func (s *Server) format(ctx context.Context, r *http.Request, body io.Reader, w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/x-chess-pgn")
+	abortOnError(s.config.NewValidator().FormatGames(ctx, body, w))
+}
+
+// abortOnError ends a PGN answer that failed, e.g. on timeout. Part of the PGN may already be
+// sent, so the connection is aborted rather than leaving the client with truncated games.
+func abortOnError(err error) {
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,268 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pgn_check/pgn"
+)
+
+const invalidGame = `[Event "Test"]
+[Date "2024-01-15"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Xx9 1-0
+`
+
+// post sends body to path of srv and returns the response with its body read
+func post(t *testing.T, srv *httptest.Server, path, encoding string, body []byte) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, srv.URL+path, bytes.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("POST %s failed: %v", path, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Reading the answer of %s failed: %v", path, err)
+	}
+	return resp, string(data)
+}
+
+// decodeValidation splits the answer of /validate into its diagnostics and summary
+func decodeValidation(t *testing.T, answer string) ([]Diagnostic, Summary) {
+	t.Helper()
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(answer))
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) == 0 {
+		t.Fatal("Expected a summary line")
+	}
+	var diagnostics []Diagnostic
+	for _, line := range lines[:len(lines)-1] {
+		var d Diagnostic
+		if err := json.Unmarshal([]byte(line), &d); err != nil {
+			t.Fatalf("Invalid diagnostic %q: %v", line, err)
+		}
+		diagnostics = append(diagnostics, d)
+	}
+	var summary Summary
+	if err := json.Unmarshal([]byte(lines[len(lines)-1]), &summary); err != nil {
+		t.Fatalf("Invalid summary %q: %v", lines[len(lines)-1], err)
+	}
+	return diagnostics, summary
+}
+
+func TestHealth(t *testing.T) {
+	srv := httptest.NewServer(New(Config{MaxConcurrent: 3}))
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + "/health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var health Health
+	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || health.Status != "ok" || health.MaxConcurrent != 3 {
+		t.Errorf("Unexpected health %d %+v", resp.StatusCode, health)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	srv := httptest.NewServer(New(Config{}))
+	defer srv.Close()
+
+	resp, answer := post(t, srv, "/validate", "", []byte(invalidGame))
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/x-ndjson" {
+		t.Fatalf("Unexpected answer %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	diagnostics, summary := decodeValidation(t, answer)
+	if len(diagnostics) != 2 || diagnostics[0].Line != 2 || diagnostics[0].Rule != "date" || diagnostics[1].Line != 5 ||
+		diagnostics[0].Severity != pgn.SeverityInfo || diagnostics[1].Severity != pgn.SeverityWarning {
+		t.Errorf("Unexpected diagnostics %+v", diagnostics)
+	}
+	// Corrections and warnings leave the game valid
+	if !summary.Valid || summary.Errors != 2 || summary.Stopped {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+
+	_, answer = post(t, srv, "/validate?max_errors=1", "", []byte(invalidGame))
+	diagnostics, summary = decodeValidation(t, answer)
+	if len(diagnostics) != 1 || !summary.Stopped {
+		t.Errorf("Expected validation to stop after 1 error, got %+v %+v", diagnostics, summary)
+	}
+
+	valid := strings.Replace(strings.Replace(invalidGame, "2024-01-15", "2024.01.15", 1), "Xx9", "Nc6", 1)
+	_, answer = post(t, srv, "/validate", "", []byte(valid))
+	if diagnostics, summary = decodeValidation(t, answer); len(diagnostics) != 0 || !summary.Valid {
+		t.Errorf("Expected a valid game, got %+v %+v", diagnostics, summary)
+	}
+
+	wrongResult := strings.Replace(invalidGame, `[Result "1-0"]`, `[Result "1-1"]`, 1)
+	_, answer = post(t, srv, "/validate", "", []byte(wrongResult))
+	if diagnostics, summary = decodeValidation(t, answer); len(diagnostics) != 3 || diagnostics[1].Severity != pgn.SeverityError || summary.Valid {
+		t.Errorf("Expected an invalid result, got %+v %+v", diagnostics, summary)
+	}
+
+	if resp, _ := post(t, srv, "/validate?max_errors=x", "", []byte(invalidGame)); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid max_errors, got %d", resp.StatusCode)
+	}
+}
+
+func TestFixAndFormat(t *testing.T) {
+	srv := httptest.NewServer(New(Config{}))
+	defer srv.Close()
+
+	resp, answer := post(t, srv, "/fix", "", []byte(invalidGame))
+	if resp.StatusCode != http.StatusOK || !strings.Contains(answer, `[Date "2024.01.15"]`) {
+		t.Errorf("Expected the corrected date, got %d %q", resp.StatusCode, answer)
+	}
+
+	resp, answer = post(t, srv, "/format", "", []byte("[Event \"Test\"]\n[Result \"*\"]\n\n1.e4   e5 *\n"))
+	if resp.StatusCode != http.StatusOK || !strings.Contains(answer, "1. e4 e5 *") {
+		t.Errorf("Expected formatted movetext, got %d %q", resp.StatusCode, answer)
+	}
+
+	if resp, _ := post(t, srv, "/validate", "", nil); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected an empty body to be accepted, got %d", resp.StatusCode)
+	}
+	resp, err := srv.Client().Get(srv.URL + "/fix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET /fix, got %d", resp.StatusCode)
+	}
+}
+
+func TestCompressedBody(t *testing.T) {
+	srv := httptest.NewServer(New(Config{}))
+	defer srv.Close()
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte(invalidGame))
+	zw.Close()
+
+	resp, answer := post(t, srv, "/fix", "gzip", compressed.Bytes())
+	if resp.StatusCode != http.StatusOK || !strings.Contains(answer, `[Date "2024.01.15"]`) {
+		t.Errorf("Expected the gzip body to be corrected, got %d %q", resp.StatusCode, answer)
+	}
+
+	if resp, _ := post(t, srv, "/fix", "gzip", []byte(invalidGame)); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a corrupt gzip body, got %d", resp.StatusCode)
+	}
+	if resp, _ := post(t, srv, "/fix", "br", []byte(invalidGame)); resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("Expected 415 for an unsupported encoding, got %d", resp.StatusCode)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	handler := New(Config{MaxBodySize: 64, MaxConcurrent: 1})
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	if resp, _ := post(t, srv, "/validate", "", []byte(invalidGame)); resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for a large body, got %d", resp.StatusCode)
+	}
+
+	// The limit applies after decompression
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write(bytes.Repeat([]byte(" "), 10000))
+	zw.Close()
+	if resp, _ := post(t, srv, "/validate", "gzip", compressed.Bytes()); resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413 for a large decompressed body, got %d", resp.StatusCode)
+	}
+
+	// Occupy the only slot
+	handler.slots <- struct{}{}
+	resp, _ := post(t, srv, "/validate", "", []byte("*\n"))
+	if resp.StatusCode != http.StatusServiceUnavailable || resp.Header.Get("Retry-After") == "" {
+		t.Errorf("Expected 503 with Retry-After when busy, got %d", resp.StatusCode)
+	}
+	<-handler.slots
+	if resp, _ := post(t, srv, "/validate", "", []byte("*\n")); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 once a slot is free, got %d", resp.StatusCode)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	srv := httptest.NewServer(New(Config{Timeout: time.Nanosecond}))
+	defer srv.Close()
+
+	// The body may already be buffered when the deadline passes, or not yet read
+	resp, answer := post(t, srv, "/validate", "", []byte(invalidGame))
+	if resp.StatusCode != http.StatusRequestTimeout {
+		if _, summary := decodeValidation(t, answer); summary.Valid || !strings.Contains(summary.Error, "timed out") {
+			t.Errorf("Expected a timeout in the summary, got %+v", summary)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/fix", strings.NewReader(invalidGame))
+	if resp, err := srv.Client().Do(req); err == nil {
+		_, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err == nil {
+			t.Error("Expected the answer of a timed out /fix to be aborted")
+		}
+	}
+}
+
+func TestSlowUpload(t *testing.T) {
+	handler := New(Config{Timeout: 200 * time.Millisecond, MaxConcurrent: 1})
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	// An upload that never ends
+	reader, writer := io.Pipe()
+	defer writer.Close()
+	go writer.Write([]byte(invalidGame))
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/validate", reader)
+	slow := make(chan int)
+	go func() {
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			slow <- 0
+			return
+		}
+		resp.Body.Close()
+		slow <- resp.StatusCode
+	}()
+
+	// The upload holds no slot
+	time.Sleep(50 * time.Millisecond)
+	if resp, _ := post(t, srv, "/validate", "", []byte("*\n")); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 during a slow upload, got %d", resp.StatusCode)
+	}
+
+	select {
+	case status := <-slow:
+		if status != http.StatusRequestTimeout {
+			t.Errorf("Expected 408 for a slow upload, got %d", status)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the slow upload to time out")
+	}
+}
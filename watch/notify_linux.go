@@ -0,0 +1,132 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+//go:build linux
+
+package watch
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// inotifyMask selects the events signaling that a file was written, created, moved or removed
+const inotifyMask = unix.IN_CLOSE_WRITE | unix.IN_CREATE | unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO
+
+// inotify reports the changes under a directory tree with Linux inotify
+type inotify struct {
+	fd      int
+	file    *os.File         // fd, read through the runtime poller so that Close stops reading
+	root    string           // watched directory tree
+	dirs    map[int32]string // watched directory by watch descriptor
+	changes chan string      // changed paths
+	done    chan struct{}    // closed by Close
+}
+
+// newNotifier watches dir and its subdirectories
+func newNotifier(dir string) (notifier, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("cannot start inotify: %v", err)
+	}
+	n := &inotify{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		root:    dir,
+		dirs:    make(map[int32]string),
+		changes: make(chan string, 64),
+		done:    make(chan struct{}),
+	}
+	if err := n.addTree(dir); err != nil {
+		n.file.Close()
+		return nil, err
+	}
+	go n.read()
+	return n, nil
+}
+
+func (n *inotify) Changes() <-chan string {
+	return n.changes
+}
+
+func (n *inotify) Close() error {
+	close(n.done)
+	return n.file.Close()
+}
+
+// addTree watches dir and the directories below it
+func (n *inotify) addTree(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		wd, err := unix.InotifyAddWatch(n.fd, path, inotifyMask)
+		if err != nil {
+			return fmt.Errorf("cannot watch %s: %v", path, err)
+		}
+		n.dirs[int32(wd)] = path
+		return nil
+	})
+}
+
+// read decodes events until the notifier is closed. An event is a header of four 32-bit
+// fields (watch descriptor, mask, cookie, name length) followed by the NUL-padded file name.
+func (n *inotify) read() {
+	defer close(n.changes)
+	buf := make([]byte, 64*1024)
+	for {
+		count, err := n.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for offset := 0; offset+unix.SizeofInotifyEvent <= count; {
+			wd := int32(binary.NativeEndian.Uint32(buf[offset:]))
+			mask := binary.NativeEndian.Uint32(buf[offset+4:])
+			length := int(binary.NativeEndian.Uint32(buf[offset+12:]))
+			name := strings.TrimRight(string(buf[offset+unix.SizeofInotifyEvent:offset+unix.SizeofInotifyEvent+length]), "\x00")
+			offset += unix.SizeofInotifyEvent + length
+
+			var path string
+			switch {
+			case mask&unix.IN_Q_OVERFLOW != 0:
+				// Events were lost: watch the directories created meanwhile, and signal the
+				// root so that every file is validated again
+				n.addTree(n.root)
+				path = n.root
+			case mask&unix.IN_IGNORED != 0:
+				delete(n.dirs, wd)
+				continue
+			default:
+				dir, ok := n.dirs[wd]
+				if !ok {
+					continue
+				}
+				path = filepath.Join(dir, name)
+				if mask&unix.IN_ISDIR != 0 && mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+					// New directories are watched too; files already in them are found by the scan
+					n.addTree(path)
+				}
+			}
+			select {
+			case n.changes <- path:
+			case <-n.done:
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,16 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+//go:build !linux
+
+package watch
+
+import "errors"
+
+// newNotifier is not available on this platform; directories are polled instead
+func newNotifier(dir string) (notifier, error) {
+	return nil, errors.New("filesystem notifications are not supported on this platform")
+}
@@ -0,0 +1,265 @@
+// PGN Check - A command-line tool for validating PGN (Portable Game Notation) files
+//
+// Author: Nazario D'Apote <nazario.dapote@gmail.com>
+// License: MIT
+// Repository: https://github.com/nazariodapote/pgn_check
+
+// Package watch revalidates the PGN files of a directory when they change, behind
+// "pgn_check watch". Only the changes in the errors of each file are reported.
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"pgn_check/pgn"
+)
+
+// DefaultInterval is the time between scans when polling
+const DefaultInterval = time.Second
+
+// settleDelay is how long to wait for more notifications after one arrives, since saving a
+// file usually causes several
+const settleDelay = 100 * time.Millisecond
+
+// Config holds the settings of a Watcher
+type Config struct {
+	Poll         bool                     // scan every Interval instead of using filesystem notifications
+	Interval     time.Duration            // time between scans when polling (default 1s)
+	NewValidator func() *pgn.PGNValidator // validator of each file (default: pgn.NewPGNValidator)
+}
+
+// Watcher keeps the errors of the PGN files of a directory up to date
+type Watcher struct {
+	config Config
+	dir    string
+	out    io.Writer
+	files  map[string]*fileState // by path
+}
+
+// fileState is the last validation of a file
+type fileState struct {
+	size    int64
+	modTime time.Time
+	errors  []pgn.ValidationError
+}
+
+// notifier reports the paths changed under a directory, and the directory itself when
+// changes may have been lost
+type notifier interface {
+	Changes() <-chan string
+	Close() error
+}
+
+// New returns a Watcher with the given settings
+func New(config Config) *Watcher {
+	if config.Interval <= 0 {
+		config.Interval = DefaultInterval
+	}
+	if config.NewValidator == nil {
+		config.NewValidator = pgn.NewPGNValidator
+	}
+	return &Watcher{config: config, files: make(map[string]*fileState)}
+}
+
+// Run validates the PGN files under dir, then revalidates the files that change until ctx is
+// canceled. It writes the errors found at first, then the errors added and removed by each
+// change, to out.
+func (w *Watcher) Run(ctx context.Context, dir string, out io.Writer) error {
+	w.dir, w.out = dir, out
+
+	// Notifications are set up before the first scan so that no change is missed
+	var changes <-chan string
+	var tick <-chan time.Time
+	mode := "filesystem notifications"
+	var n notifier
+	var err error
+	if !w.config.Poll {
+		n, err = newNotifier(dir)
+	}
+	if w.config.Poll || err != nil {
+		ticker := time.NewTicker(w.config.Interval)
+		defer ticker.Stop()
+		tick = ticker.C
+		mode = fmt.Sprintf("polling every %v", w.config.Interval)
+	} else {
+		defer n.Close()
+		changes = n.Changes()
+	}
+
+	if err := w.scan(nil); err != nil {
+		return err
+	}
+	invalid := 0
+	for _, state := range w.files {
+		if countSeverities(state.errors)[pgn.SeverityError] > 0 {
+			invalid++
+		}
+	}
+	fmt.Fprintf(out, "✓ Watching %d PGN files in %s (%d with errors), using %s\n", len(w.files), dir, invalid, mode)
+
+	for {
+		touched := map[string]bool{}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick:
+		case path, ok := <-changes:
+			if !ok {
+				return errors.New("filesystem notifications stopped")
+			}
+			touched[path] = true
+			settle := time.After(settleDelay)
+		collect:
+			for {
+				select {
+				case path, ok := <-changes:
+					if !ok {
+						break collect
+					}
+					touched[path] = true
+				case <-settle:
+					break collect
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		}
+		if err := w.scan(touched); err != nil {
+			return err
+		}
+	}
+}
+
+// isPGN reports whether a file name has the .pgn extension
+func isPGN(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".pgn")
+}
+
+// scan revalidates the files that were touched, whose size or modification time changed or
+// that are new, and forgets the files removed. Touching the watched directory revalidates
+// every file.
+func (w *Watcher) scan(touched map[string]bool) error {
+	var paths []string
+	infos := map[string]fs.FileInfo{}
+	err := filepath.WalkDir(w.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == w.dir {
+				return err
+			}
+			return nil // e.g. a directory removed while scanning
+		}
+		if !d.Type().IsRegular() || !isPGN(path) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		paths = append(paths, path)
+		infos[path] = info
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot scan directory: %v", err)
+	}
+
+	for _, path := range paths {
+		info := infos[path]
+		state := w.files[path]
+		if state != nil && !touched[path] && !touched[w.dir] && state.size == info.Size() && state.modTime.Equal(info.ModTime()) {
+			continue
+		}
+		var old []pgn.ValidationError
+		if state != nil {
+			old = state.errors
+		}
+		found := w.config.NewValidator().ValidateFile(path)
+		w.files[path] = &fileState{size: info.Size(), modTime: info.ModTime(), errors: found}
+		w.report(path, old, found, false)
+	}
+
+	var removed []string
+	for path := range w.files {
+		if _, ok := infos[path]; !ok {
+			removed = append(removed, path)
+		}
+	}
+	sort.Strings(removed)
+	for _, path := range removed {
+		w.report(path, w.files[path].errors, nil, true)
+		delete(w.files, path)
+	}
+	return nil
+}
+
+// report writes the errors of a file added and removed by a change, nothing if its errors
+// did not change
+func (w *Watcher) report(path string, old, current []pgn.ValidationError, removed bool) {
+	added, fixed := diffErrors(old, current)
+	if len(added) == 0 && len(fixed) == 0 {
+		return
+	}
+
+	name := path
+	if rel, err := filepath.Rel(w.dir, path); err == nil {
+		name = rel
+	}
+	switch {
+	case removed:
+		fmt.Fprintf(w.out, "✓ %s: removed\n", name)
+	case len(current) == 0:
+		fmt.Fprintf(w.out, "✓ %s: valid\n", name)
+	default:
+		fmt.Fprintf(w.out, "✗ %s: %s\n", name, pgn.DescribeCounts(countSeverities(current)))
+	}
+	for _, e := range fixed {
+		fmt.Fprintf(w.out, "  - %s\n", e)
+	}
+	for _, e := range added {
+		fmt.Fprintf(w.out, "  + %s\n", e)
+	}
+}
+
+// countSeverities returns the number of errors of each severity
+func countSeverities(errors []pgn.ValidationError) map[pgn.Severity]int {
+	counts := map[pgn.Severity]int{}
+	for _, e := range errors {
+		counts[e.Severity]++
+	}
+	return counts
+}
+
+// diffErrors returns the errors of current missing from old and the errors of old missing
+// from current. Errors are compared by rule and message, so that an error that only moved to
+// another line is not reported again.
+func diffErrors(old, current []pgn.ValidationError) (added, fixed []pgn.ValidationError) {
+	key := func(e pgn.ValidationError) string {
+		return e.Rule + "\x00" + e.Message
+	}
+	count := map[string]int{}
+	for _, e := range old {
+		count[key(e)]++
+	}
+	for _, e := range current {
+		if count[key(e)] > 0 {
+			count[key(e)]--
+		} else {
+			added = append(added, e)
+		}
+	}
+	for _, e := range old {
+		if count[key(e)] > 0 {
+			count[key(e)]--
+			fixed = append(fixed, e)
+		}
+	}
+	return added, fixed
+}
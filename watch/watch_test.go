@@ -0,0 +1,139 @@
+package watch
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pgn_check/pgn"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitFor waits until out contains text
+func waitFor(t *testing.T, out *syncBuffer, text string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(out.String(), text) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %q, output:\n%s", text, out.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+const badDate = "[Event \"Test\"]\n[Date \"15/01/2024\"]\n[Result \"*\"]\n\n1. e4 e5 *\n"
+
+func testWatch(t *testing.T, config Config) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.pgn"), []byte(badDate), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a PGN file"), 0644)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &syncBuffer{}
+	done := make(chan error)
+	go func() { done <- New(config).Run(ctx, dir, out) }()
+
+	waitFor(t, out, "✓ Watching 1 PGN files")
+	if !strings.Contains(out.String(), "✗ a.pgn: 1 info\n  + Line 2: Date auto-corrected") {
+		t.Errorf("Expected the initial errors, got:\n%s", out.String())
+	}
+
+	// Moving the error to another line changes nothing
+	os.WriteFile(filepath.Join(dir, "a.pgn"), []byte("\n"+badDate), 0644)
+	// Fixing it is reported, at its current line
+	time.Sleep(300 * time.Millisecond)
+	os.WriteFile(filepath.Join(dir, "a.pgn"), []byte(strings.Replace(badDate, "15/01/2024", "2024.01.15", 1)), 0644)
+	waitFor(t, out, "✓ a.pgn: valid\n  - Line 3: Date auto-corrected")
+
+	// New files in new directories are found
+	os.Mkdir(filepath.Join(dir, "sub"), 0755)
+	time.Sleep(300 * time.Millisecond)
+	os.WriteFile(filepath.Join(dir, "sub", "b.pgn"), []byte(strings.Replace(badDate, "Result \"*\"", "Result \"2-0\"", 1)), 0644)
+	waitFor(t, out, "✗ "+filepath.Join("sub", "b.pgn")+": 1 error, 1 info")
+	os.Remove(filepath.Join(dir, "sub", "b.pgn"))
+	waitFor(t, out, "✓ "+filepath.Join("sub", "b.pgn")+": removed")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run failed: %v", err)
+	}
+	if strings.Count(out.String(), "Date auto-corrected") != 4 {
+		t.Errorf("Expected only changes to be reported, got:\n%s", out.String())
+	}
+}
+
+func TestWatchNotifications(t *testing.T) {
+	testWatch(t, Config{})
+}
+
+func TestWatchPolling(t *testing.T) {
+	testWatch(t, Config{Poll: true, Interval: 20 * time.Millisecond})
+}
+
+func TestScanAll(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.pgn"), []byte(badDate), 0644); err != nil {
+		t.Fatal(err)
+	}
+	validated := 0
+	w := New(Config{NewValidator: func() *pgn.PGNValidator {
+		validated++
+		return pgn.NewPGNValidator()
+	}})
+	w.dir, w.out = dir, &bytes.Buffer{}
+
+	// Unchanged files are validated again only when the directory is touched, e.g. after
+	// notifications were lost
+	for _, touched := range []map[string]bool{nil, {}, {dir: true}} {
+		if err := w.scan(touched); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if validated != 2 {
+		t.Errorf("Expected 2 validations, got %d", validated)
+	}
+}
+
+func TestDiffErrors(t *testing.T) {
+	old := []pgn.ValidationError{
+		{Line: 2, Message: "Invalid date", Rule: pgn.RuleDate},
+		{Line: 5, Message: "Invalid move", Rule: pgn.RuleMoveNotation},
+		{Line: 6, Message: "Invalid move", Rule: pgn.RuleMoveNotation},
+	}
+	current := []pgn.ValidationError{
+		{Line: 3, Message: "Invalid date", Rule: pgn.RuleDate},
+		{Line: 7, Message: "Invalid move", Rule: pgn.RuleMoveNotation},
+		{Line: 8, Message: "Invalid result", Rule: pgn.RuleResult},
+	}
+	added, fixed := diffErrors(old, current)
+	if len(added) != 1 || added[0].Line != 8 {
+		t.Errorf("Expected the result error to be added, got %v", added)
+	}
+	if len(fixed) != 1 || fixed[0].Rule != pgn.RuleMoveNotation {
+		t.Errorf("Expected one move error to be fixed, got %v", fixed)
+	}
+}